@@ -1,56 +1,32 @@
 package main
 
 import (
+	"context"
 	"log"
 	"os"
-	"strings"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"transfer-tool/internal/commands"
+	"transfer-tool/internal/secrets"
 
 	"github.com/urfave/cli/v2"
 )
 
 // init 程序初始化时自动加载环境变量
 func init() {
-	// 自动查找并加载.env文件
-	envFiles := []string{".env", "configs/.env", "configs/env.example"}
+	// 自动查找并加载.env文件（configs/env.example 只是模板，不会加载）
+	envFiles := []string{".env", "configs/.env"}
 
 	for _, envFile := range envFiles {
 		if _, err := os.Stat(envFile); err == nil {
-			loadEnvFile(envFile)
+			secrets.LoadEnvFile(envFile)
 			break
 		}
 	}
 }
 
-// loadEnvFile 加载环境变量文件
-func loadEnvFile(envFile string) {
-	// 读取文件内容
-	content, err := os.ReadFile(envFile)
-	if err != nil {
-		return
-	}
-
-	// 解析环境变量并设置到系统环境变量中
-	lines := strings.Split(string(content), "\n")
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if line == "" || strings.HasPrefix(line, "#") {
-			continue
-		}
-
-		parts := strings.SplitN(line, "=", 2)
-		if len(parts) == 2 {
-			key := strings.TrimSpace(parts[0])
-			value := strings.TrimSpace(parts[1])
-			// 只有当环境变量不存在时才设置
-			if os.Getenv(key) == "" {
-				os.Setenv(key, value)
-			}
-		}
-	}
-}
-
 func main() {
 	app := &cli.App{
 		Name:  "transfer-tool",
@@ -60,7 +36,24 @@ func main() {
 				Name:    "network",
 				Aliases: []string{"n"},
 				Value:   "sepolia",
-				Usage:   "网络选择: sepolia, goerli, mainnet, bnb, polygon",
+				Usage:   "网络选择: sepolia, goerli, mainnet, bnb, polygon，或 configs/config.yaml 中 networks 定义的网络；auto 表示根据RPC节点链ID自动识别",
+			},
+			&cli.StringFlag{
+				Name:  "rpc",
+				Usage: "指定RPC节点URL，优先于配置文件和环境变量",
+			},
+			&cli.StringFlag{
+				Name:  "address-book",
+				Usage: "地址簿文件路径，默认 configs/addressbook.yaml",
+			},
+			&cli.DurationFlag{
+				Name:  "timeout",
+				Value: 30 * time.Second,
+				Usage: "单次RPC调用超时时间，如 10s、1m；0 表示不限制",
+			},
+			&cli.Float64Flag{
+				Name:  "rps",
+				Usage: "每个RPC节点每秒最多请求数（批量请求按其中的调用数计算），0 表示不限流；默认使用网络配置 rpc_rps",
 			},
 		},
 		Commands: []*cli.Command{
@@ -68,21 +61,120 @@ func main() {
 				Name:      "send",
 				Aliases:   []string{"s"},
 				Usage:     "单笔转账",
-				ArgsUsage: "<recipient_address> <amount_in_eth>",
-				Flags: []cli.Flag{
+				ArgsUsage: "<recipient_address|label> <amount>",
+				Flags: append([]cli.Flag{
+					yesFlag(),
+					&cli.StringFlag{
+						Name:  "from",
+						Usage: "发送钱包（索引、地址或地址簿标签），默认第一个钱包",
+					},
 					&cli.BoolFlag{
-						Name:    "yes",
-						Aliases: []string{"y"},
-						Usage:   "跳过确认提示",
+						Name:  "auto",
+						Usage: "自动选择余额最高且足够支付金额和Gas的钱包",
 					},
-				},
+				}, gasFlags()...),
 				Action: commands.SendCommand,
 			},
 			{
 				Name:    "balance",
 				Aliases: []string{"b"},
 				Usage:   "查询所有钱包余额",
-				Action:  commands.BalanceCommand,
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "token",
+						Usage: "查询ERC-20代币余额（合约地址或地址簿标签）",
+					},
+					&cli.Uint64Flag{
+						Name:  "block",
+						Usage: "查询指定区块的历史余额（需要归档节点）",
+					},
+					&cli.StringFlag{
+						Name:  "at",
+						Usage: "查询指定时间的历史余额：Unix时间戳、2006-01-02、2006-01-02 15:04:05 或 RFC3339（需要归档节点）",
+					},
+					&cli.BoolFlag{
+						Name:  "watch",
+						Usage: "持续监控余额：WebSocket节点订阅新区块，否则按 --interval 轮询；标注变化并在低于最低余额时告警",
+					},
+					&cli.DurationFlag{
+						Name:  "interval",
+						Value: 15 * time.Second,
+						Usage: "监控模式的轮询间隔",
+					},
+					&cli.StringFlag{
+						Name:  "min",
+						Usage: "最低余额告警阈值，默认使用网络配置 min_balance",
+					},
+					&cli.BoolFlag{
+						Name:  "all-networks",
+						Usage: "并发查询所有已配置网络，输出 钱包 × 网络 余额矩阵",
+					},
+					&cli.StringSliceFlag{
+						Name:  "networks",
+						Usage: "并发查询指定的多个网络（逗号分隔），输出 钱包 × 网络 余额矩阵",
+					},
+				},
+				Action: commands.BalanceCommand,
+			},
+			{
+				Name:      "sweep",
+				Usage:     "将所有钱包的余额扣除手续费后归集到目标地址",
+				ArgsUsage: "<destination|label>",
+				Flags: append([]cli.Flag{
+					yesFlag(),
+					&cli.StringFlag{
+						Name:  "token",
+						Usage: "先归集ERC-20代币（合约地址或地址簿标签），再归集原生代币",
+					},
+					&cli.BoolFlag{
+						Name:  "no-native",
+						Usage: "只归集 --token 指定的代币，保留原生代币",
+					},
+				}, gasFlags()...),
+				Action: commands.SweepCommand,
+			},
+			{
+				Name:  "fund",
+				Usage: "将托管钱包余额补足到目标值：从出资钱包转出差额，或在钱包之间再平衡",
+				Flags: append([]cli.Flag{
+					yesFlag(),
+					&cli.StringFlag{
+						Name:  "target",
+						Usage: "目标余额（原生代币）",
+					},
+					&cli.StringFlag{
+						Name:  "from",
+						Usage: "出资钱包（索引、地址或地址簿标签）",
+					},
+					&cli.BoolFlag{
+						Name:  "rebalance",
+						Usage: "由高于目标的钱包将超出部分转给低于目标的钱包",
+					},
+				}, gasFlags()...),
+				Action: commands.FundCommand,
+			},
+			{
+				Name:  "history",
+				Usage: "列出托管钱包的原生代币和ERC-20转入转出记录",
+				Flags: []cli.Flag{
+					&cli.Uint64Flag{
+						Name:  "from-block",
+						Usage: "起始区块，默认为结束区块前 1000 个区块",
+					},
+					&cli.Uint64Flag{
+						Name:  "to-block",
+						Usage: "结束区块，默认为最新区块",
+					},
+					&cli.StringFlag{
+						Name:  "csv",
+						Usage: "导出CSV文件路径",
+					},
+					&cli.BoolFlag{
+						Name:  "no-cache",
+						Usage: "不读取和更新本地缓存 data/history_<chain_id>.json",
+					},
+				},
+				Action: commands.HistoryCommand,
 			},
 			{
 				Name:      "batch",
@@ -99,10 +191,239 @@ func main() {
 				},
 				Action: commands.BatchCommand,
 			},
+			{
+				Name:      "sign-message",
+				Usage:     "使用托管钱包进行消息签名 (EIP-191 personal_sign)",
+				ArgsUsage: "<message>",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "from",
+						Value: "0",
+						Usage: "签名钱包（索引、地址或地址簿标签）",
+					},
+					&cli.BoolFlag{
+						Name:  "hex",
+						Usage: "消息为0x开头的十六进制数据",
+					},
+				},
+				Action: commands.SignMessageCommand,
+			},
+			{
+				Name:      "sign-typed-data",
+				Usage:     "使用托管钱包进行结构化数据签名 (EIP-712)",
+				ArgsUsage: "<json|json_file>",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "from",
+						Value: "0",
+						Usage: "签名钱包（索引、地址或地址簿标签）",
+					},
+				},
+				Action: commands.SignTypedDataCommand,
+			},
+			{
+				Name:      "verify",
+				Usage:     "验证签名并恢复签名者地址",
+				ArgsUsage: "<message|json|json_file> <signature>",
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:  "typed",
+						Usage: "按EIP-712结构化数据验证",
+					},
+					&cli.BoolFlag{
+						Name:  "hex",
+						Usage: "消息为0x开头的十六进制数据",
+					},
+					&cli.StringFlag{
+						Name:  "address",
+						Usage: "期望的签名者地址",
+					},
+				},
+				Action: commands.VerifyCommand,
+			},
+			{
+				Name:      "call",
+				Usage:     "只读调用合约方法（eth_call）并解码返回值",
+				ArgsUsage: "<contract|label> <function> [args...]",
+				Flags: append(contractFlags(),
+					&cli.StringFlag{
+						Name:  "from",
+						Usage: "调用方（钱包索引、任意地址或地址簿标签），默认第一个钱包",
+					},
+					&cli.StringFlag{
+						Name:  "value",
+						Usage: "调用附带的原生代币金额",
+					},
+					&cli.Uint64Flag{
+						Name:  "block",
+						Usage: "在指定区块执行调用（需要归档节点）",
+					},
+				),
+				Action: commands.CallCommand,
+			},
+			{
+				Name:      "exec",
+				Usage:     "使用托管钱包发送合约调用交易",
+				ArgsUsage: "<contract|label> <function> [args...]",
+				Flags: append(append(contractFlags(),
+					yesFlag(),
+					&cli.StringFlag{
+						Name:  "from",
+						Usage: "发送钱包（索引、地址或地址簿标签），默认第一个钱包",
+					},
+					&cli.StringFlag{
+						Name:  "value",
+						Usage: "交易附带的原生代币金额（payable 方法）",
+					},
+				), gasFlags()...),
+				Action: commands.ExecCommand,
+			},
+			{
+				Name:      "deploy",
+				Usage:     "使用托管钱包部署合约，等待打包后显示合约地址",
+				ArgsUsage: "--bytecode <file> [--abi <file>] [constructor_args...]",
+				Flags: append([]cli.Flag{
+					yesFlag(),
+					&cli.StringFlag{
+						Name:  "bytecode",
+						Usage: "字节码文件（十六进制文本，或Hardhat/Foundry编译产物）",
+					},
+					&cli.StringFlag{
+						Name:  "abi",
+						Usage: "ABI文件，用于编码构造函数参数；未指定时使用编译产物中的ABI",
+					},
+					&cli.StringFlag{
+						Name:  "from",
+						Usage: "部署钱包（索引、地址或地址簿标签），默认第一个钱包",
+					},
+					&cli.StringFlag{
+						Name:  "value",
+						Usage: "部署时附带的原生代币金额（payable 构造函数）",
+					},
+				}, gasFlags()...),
+				Action: commands.DeployCommand,
+			},
+			{
+				Name:  "tx",
+				Usage: "交易查询、加速与取消",
+				Subcommands: []*cli.Command{
+					{
+						Name:      "status",
+						Usage:     "查询交易状态：是否打包、执行结果、确认数和回滚原因",
+						ArgsUsage: "<tx_hash>",
+						Action:    commands.TxStatusCommand,
+					},
+					{
+						Name:      "show",
+						Usage:     "查询交易详情：类型、金额、费用、Gas使用、回滚原因和代币转账",
+						ArgsUsage: "<tx_hash>",
+						Action:    commands.TxShowCommand,
+					},
+					{
+						Name:      "speedup",
+						Usage:     "以相同nonce和更高费用重新广播待打包的交易",
+						ArgsUsage: "<tx_hash>",
+						Flags:     append([]cli.Flag{yesFlag()}, gasFlags()...),
+						Action:    commands.TxSpeedUpCommand,
+					},
+					{
+						Name:      "cancel",
+						Usage:     "以相同nonce向自己转账0，取消待打包的交易",
+						ArgsUsage: "<tx_hash>",
+						Flags:     append([]cli.Flag{yesFlag()}, gasFlags()...),
+						Action:    commands.TxCancelCommand,
+					},
+				},
+			},
+			{
+				Name:  "rpc",
+				Usage: "RPC节点工具",
+				Subcommands: []*cli.Command{
+					{
+						Name:      "check",
+						Usage:     "诊断各网络RPC节点：连通性、链ID、最新区块、客户端版本、EIP-1559及方法支持",
+						ArgsUsage: "[network...]",
+						Action:    commands.RPCCheckCommand,
+					},
+				},
+			},
+			{
+				Name:  "secrets",
+				Usage: "密钥来源管理（文件引用、命令输出、加密密钥文件）",
+				Subcommands: []*cli.Command{
+					{
+						Name:      "encrypt",
+						Usage:     "将明文KEY=VALUE文件加密为密钥文件",
+						ArgsUsage: "<plain_env_file>",
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name:    "out",
+								Aliases: []string{"o"},
+								Usage:   "输出路径，默认 SECRETS_FILE 或 ~/.transfer-tool/secrets.enc",
+							},
+						},
+						Action: commands.SecretsEncryptCommand,
+					},
+					{
+						Name:   "check",
+						Usage:  "检查各密钥的来源（不显示密钥内容）",
+						Action: commands.SecretsCheckCommand,
+					},
+				},
+			},
 		},
 	}
 
-	if err := app.Run(os.Args); err != nil {
+	// Ctrl-C/SIGTERM 取消命令上下文；之后恢复默认处理，再次按 Ctrl-C 可强制退出
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+
+	if err := app.RunContext(ctx, os.Args); err != nil {
 		log.Fatal(err)
 	}
 }
+
+// yesFlag 跳过确认提示
+func yesFlag() cli.Flag {
+	return &cli.BoolFlag{
+		Name:    "yes",
+		Aliases: []string{"y"},
+		Usage:   "跳过确认提示",
+	}
+}
+
+// gasFlags Gas策略相关参数，send、sweep、fund、exec、deploy 和 tx 子命令共用
+func gasFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:  "gas",
+			Usage: "Gas策略: slow, standard, fast 或 custom（默认 standard；指定 --max-fee 时为 custom）",
+		},
+		&cli.StringFlag{
+			Name:  "max-fee",
+			Usage: "custom 策略的 maxFeePerGas（Gwei），传统网络为 gasPrice",
+		},
+		&cli.StringFlag{
+			Name:  "priority-fee",
+			Usage: "custom 策略的 maxPriorityFeePerGas（Gwei），默认使用节点建议值",
+		},
+	}
+}
+
+// contractFlags 指定调用方法的参数，call 和 exec 子命令共用
+func contractFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:  "abi",
+			Usage: "ABI文件（ABI数组或Hardhat/Foundry编译产物），函数可只写方法名",
+		},
+		&cli.StringFlag{
+			Name:  "data",
+			Usage: "直接使用0x开头的原始调用数据，不指定函数和参数",
+		},
+	}
+}
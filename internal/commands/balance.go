@@ -3,31 +3,35 @@ package commands
 import (
 	"fmt"
 	"math/big"
+	"time"
 
 	"transfer-tool/internal/config"
 	"transfer-tool/internal/wallet"
 
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/urfave/cli/v2"
 )
 
 // BalanceCommand 余额查询命令
 func BalanceCommand(c *cli.Context) error {
-	network := c.String("network")
-	appConfig := config.LoadAppConfig()
-	envFile := appConfig.EnvFile
-
-	// 尝试加载全局RPC配置
-	var customRPCs map[string]string
-	if globalRPC, err := config.LoadGlobalRPCConfig(); err == nil {
-		customRPCs = globalRPC
+	// 多网络余额总览
+	if c.Bool("all-networks") || len(c.StringSlice("networks")) > 0 {
+		return balanceOverview(c)
 	}
 
 	// 创建钱包管理器
-	wm, err := wallet.NewManagerWithRPC(envFile, network, customRPCs)
+	wm, err := newWalletManager(c)
+	if err != nil {
+		return err
+	}
+	defer wm.Close()
+
+	// 加载地址簿
+	book, err := loadAddressBook(c)
 	if err != nil {
 		return err
 	}
-	defer wm.GetClient().Close()
 
 	// 获取所有地址
 	addresses := wm.GetAddresses()
@@ -35,36 +39,235 @@ func BalanceCommand(c *cli.Context) error {
 		return fmt.Errorf("没有可用的钱包地址")
 	}
 
-	// 查询余额
-	fmt.Printf("Wallet Balances on %s (ChainID: %s):\n",
-		wm.GetNetworkConfig().Name, wm.GetChainID().String())
-
-	totalBalance := big.NewInt(0)
-	hasZeroBalance := false
+	// 加载只读监控地址（无需私钥）
+	watchAddresses, err := config.LoadWatchAddresses()
+	if err != nil {
+		return err
+	}
 
-	for _, address := range addresses {
-		balance, err := wm.GetBalance(address)
+	// 指定代币时查询ERC-20余额（支持合约地址或地址簿标签）
+	var token *wallet.TokenInfo
+	if tokenSpec := c.String("token"); tokenSpec != "" {
+		tokenAddr, err := book.Resolve(tokenSpec)
 		if err != nil {
-			fmt.Printf("- %s : 查询失败 (%v)\n", address.Hex(), err)
-			continue
+			return fmt.Errorf("无效的代币: %v", err)
 		}
+		token, err = wm.GetTokenInfo(c.Context, common.HexToAddress(tokenAddr))
+		if err != nil {
+			return err
+		}
+	}
 
-		balanceStr := wallet.FormatAmount(balance)
-		if balance.Cmp(big.NewInt(0)) == 0 {
-			fmt.Printf("- %s : %s ETH ⚠️\n", address.Hex(), balanceStr)
-			hasZeroBalance = true
+	view := &balanceView{
+		wm:      wm,
+		book:    book,
+		token:   token,
+		managed: len(addresses),
+		symbol:  wm.Symbol(),
+		format:  wm.FormatNative,
+	}
+	if token != nil {
+		view.symbol = token.Symbol
+		view.format = token.Format
+	}
+
+	// 最低余额告警阈值：--min 优先，否则使用网络配置 min_balance（仅原生代币）
+	if minStr := c.String("min"); minStr != "" {
+		if token != nil {
+			view.minBalance, err = token.Parse(minStr)
 		} else {
-			fmt.Printf("- %s : %s ETH\n", address.Hex(), balanceStr)
+			view.minBalance, err = wm.ParseNative(minStr)
+		}
+		if err != nil {
+			return fmt.Errorf("无效的最低余额: %v", err)
+		}
+	} else if token == nil {
+		view.minBalance = wm.GetNetworkConfig().MinBalance
+	}
+
+	// 托管钱包和监控地址合并查询，所有余额来自同一区块
+	managed := make(map[common.Address]bool, len(addresses))
+	for _, address := range addresses {
+		managed[address] = true
+	}
+	queryAddresses := append([]common.Address{}, addresses...)
+	for _, address := range watchAddresses {
+		if !managed[address] {
+			queryAddresses = append(queryAddresses, address)
 		}
+	}
 
-		totalBalance.Add(totalBalance, balance)
+	if c.Bool("watch") {
+		if c.IsSet("block") || c.IsSet("at") {
+			return fmt.Errorf("--watch 不能与 --block 或 --at 同时使用")
+		}
+		return watchBalances(c.Context, view, queryAddresses, c.Duration("interval"))
 	}
 
-	fmt.Printf("Total: %s ETH\n", wallet.FormatAmount(totalBalance))
+	// 历史余额：指定区块号或时间（取不晚于该时间的最后一个区块）
+	block, blockTime, err := resolveHistoricalBlock(c, wm)
+	if err != nil {
+		return err
+	}
 
-	if hasZeroBalance {
+	snapshot, err := wm.GetBalanceSnapshot(c.Context, queryAddresses, token, block)
+	if err != nil {
+		return err
+	}
+
+	view.printHeader()
+	if block != nil {
+		fmt.Printf("Block: %d @ %s (%s)\n", snapshot.BlockNumber, blockTime.Format("2006-01-02 15:04:05 MST"), snapshotSource(snapshot))
+	} else {
+		fmt.Printf("Block: %d (%s)\n", snapshot.BlockNumber, snapshotSource(snapshot))
+	}
+
+	result := view.render(snapshot, nil)
+
+	if len(result.belowMin) > 0 {
+		fmt.Printf("\n⚠️  %d 个地址余额低于最低余额 %s %s:\n", len(result.belowMin), view.format(view.minBalance), view.symbol)
+		for _, address := range result.belowMin {
+			fmt.Printf("   - %s\n", book.Display(address.Hex()))
+		}
+	} else if result.hasZero {
 		fmt.Printf("\n⚠️  部分钱包余额为0，可能影响转账操作\n")
 	}
 
 	return nil
 }
+
+// balanceView 余额表格的显示参数
+type balanceView struct {
+	wm         *wallet.Manager
+	book       *config.AddressBook
+	token      *wallet.TokenInfo
+	managed    int // 查询地址中前 managed 个为托管钱包，其余为只读监控地址
+	symbol     string
+	format     func(*big.Int) string
+	minBalance *big.Int
+}
+
+// balanceRenderResult 余额表格的汇总结果
+type balanceRenderResult struct {
+	balances map[common.Address]*big.Int
+	belowMin []common.Address
+	hasZero  bool
+}
+
+// printHeader 输出表头
+func (v *balanceView) printHeader() {
+	if v.token != nil {
+		fmt.Printf("Token Balances of %s (%s) on %s (ChainID: %s):\n",
+			v.token.Symbol, v.token.Address.Hex(), v.wm.GetNetworkConfig().Name, v.wm.GetChainID().String())
+	} else {
+		fmt.Printf("Wallet Balances on %s (ChainID: %s):\n",
+			v.wm.GetNetworkConfig().Name, v.wm.GetChainID().String())
+	}
+}
+
+// render 输出托管钱包和监控地址余额；previous 不为空时标注与上次查询相比的变化
+func (v *balanceView) render(snapshot *wallet.BalanceSnapshot, previous map[common.Address]*big.Int) balanceRenderResult {
+	result := balanceRenderResult{balances: make(map[common.Address]*big.Int, len(snapshot.Results))}
+
+	printRows := func(rows []wallet.BalanceResult, tag string) *big.Int {
+		total := big.NewInt(0)
+		for _, row := range rows {
+			name := v.book.Display(row.Address.Hex()) + tag
+			if row.Err != nil {
+				fmt.Printf("- %s : 查询失败 (%v)\n", name, row.Err)
+				continue
+			}
+
+			balance := row.Balance
+			result.balances[row.Address] = balance
+			line := fmt.Sprintf("- %s : %s %s", name, v.format(balance), v.symbol)
+
+			switch {
+			case v.minBalance != nil && balance.Cmp(v.minBalance) < 0:
+				line += " 🔻"
+				result.belowMin = append(result.belowMin, row.Address)
+			case balance.Sign() == 0 && tag == "":
+				line += " ⚠️"
+			}
+			if balance.Sign() == 0 && tag == "" {
+				result.hasZero = true
+			}
+
+			if prev, ok := previous[row.Address]; ok {
+				line += v.formatDelta(new(big.Int).Sub(balance, prev))
+			}
+
+			fmt.Println(line)
+			total.Add(total, balance)
+		}
+		return total
+	}
+
+	total := printRows(snapshot.Results[:v.managed], "")
+	fmt.Printf("Total: %s %s\n", v.format(total), v.symbol)
+
+	// 只读监控地址单独列出，不计入托管钱包合计
+	if len(snapshot.Results) > v.managed {
+		fmt.Printf("\nWatch-only Addresses:\n")
+		watchTotal := printRows(snapshot.Results[v.managed:], " [watch-only]")
+		fmt.Printf("Watch-only Total: %s %s\n", v.format(watchTotal), v.symbol)
+	}
+
+	return result
+}
+
+// formatDelta 格式化余额变化，增加显示为绿色、减少显示为红色
+func (v *balanceView) formatDelta(delta *big.Int) string {
+	switch delta.Sign() {
+	case 1:
+		return fmt.Sprintf("  \033[32m(+%s)\033[0m", v.format(delta))
+	case -1:
+		return fmt.Sprintf("  \033[31m(-%s)\033[0m", v.format(new(big.Int).Neg(delta)))
+	}
+	return ""
+}
+
+// snapshotSource 余额查询方式
+func snapshotSource(snapshot *wallet.BalanceSnapshot) string {
+	if snapshot.Multicall {
+		return "Multicall3"
+	}
+	return "JSON-RPC batch"
+}
+
+// resolveHistoricalBlock 解析 --block / --at 参数并检查节点是否保留该区块的状态，未指定时返回空表示最新区块
+func resolveHistoricalBlock(c *cli.Context, wm *wallet.Manager) (*big.Int, time.Time, error) {
+	if c.IsSet("block") && c.IsSet("at") {
+		return nil, time.Time{}, fmt.Errorf("--block 和 --at 不能同时使用")
+	}
+
+	var header *types.Header
+	switch {
+	case c.IsSet("block"):
+		number := new(big.Int).SetUint64(c.Uint64("block"))
+		if err := wm.CheckHistoricalState(c.Context, number); err != nil {
+			return nil, time.Time{}, err
+		}
+		var err error
+		header, err = wm.HeaderByNumber(c.Context, number)
+		if err != nil {
+			return nil, time.Time{}, err
+		}
+	case c.IsSet("at"):
+		t, err := parseTimestamp(c.String("at"))
+		if err != nil {
+			return nil, time.Time{}, err
+		}
+		header, err = wm.BlockAtTime(c.Context, t)
+		if err != nil {
+			return nil, time.Time{}, err
+		}
+		if err := wm.CheckHistoricalState(c.Context, header.Number); err != nil {
+			return nil, time.Time{}, err
+		}
+	default:
+		return nil, time.Time{}, nil
+	}
+
+	return header.Number, time.Unix(int64(header.Time), 0), nil
+}
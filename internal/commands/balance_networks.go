@@ -0,0 +1,217 @@
+package commands
+
+import (
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+	"sync"
+	"text/tabwriter"
+
+	"transfer-tool/internal/config"
+	"transfer-tool/internal/wallet"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/urfave/cli/v2"
+)
+
+// networkBalances 单个网络的余额查询结果
+type networkBalances struct {
+	name      string
+	config    wallet.NetworkConfig
+	addresses []common.Address
+	snapshot  *wallet.BalanceSnapshot
+	err       error
+}
+
+// balanceOverview 解析 --all-networks / --networks 参数并输出多网络余额总览
+func balanceOverview(c *cli.Context) error {
+	if c.Bool("all-networks") && len(c.StringSlice("networks")) > 0 {
+		return fmt.Errorf("--all-networks 和 --networks 不能同时使用")
+	}
+
+	available, err := wallet.NetworkNames()
+	if err != nil {
+		return err
+	}
+
+	names := available
+	if !c.Bool("all-networks") {
+		known := make(map[string]bool, len(available))
+		for _, name := range available {
+			known[name] = true
+		}
+		names = nil
+		for _, name := range c.StringSlice("networks") {
+			name = wallet.NormalizeNetworkName(name)
+			if !known[name] {
+				return fmt.Errorf("不支持的网络: %s (可用网络: %s)", name, strings.Join(available, ", "))
+			}
+			names = append(names, name)
+		}
+	}
+
+	book, err := loadAddressBook(c)
+	if err != nil {
+		return err
+	}
+	watchAddresses, err := config.LoadWatchAddresses()
+	if err != nil {
+		return err
+	}
+
+	return multiNetworkBalances(c, names, book, watchAddresses)
+}
+
+// multiNetworkBalances 并发查询多个网络的原生代币余额，输出 钱包 × 网络 矩阵
+// 单个网络未配置RPC或不可达时该列显示为不可用，不影响其他网络
+func multiNetworkBalances(c *cli.Context, names []string, book *config.AddressBook, watchAddresses []common.Address) error {
+	if c.String("token") != "" || c.IsSet("block") || c.IsSet("at") || c.Bool("watch") {
+		return fmt.Errorf("多网络查询不支持 --token、--block、--at 和 --watch")
+	}
+
+	appConfig := config.LoadAppConfig()
+	var customRPCs map[string]string
+	if globalRPC, err := config.LoadGlobalRPCConfig(); err == nil {
+		customRPCs = globalRPC
+	}
+
+	results := make([]networkBalances, len(names))
+	var wg sync.WaitGroup
+	for i, name := range names {
+		wg.Add(1)
+		go func(i int, name string) {
+			defer wg.Done()
+			results[i] = queryNetworkBalances(c, appConfig.EnvFile, name, customRPCs, watchAddresses)
+		}(i, name)
+	}
+	wg.Wait()
+
+	// 各网络使用相同的私钥，取任一可用网络的钱包地址
+	var addresses []common.Address
+	for _, result := range results {
+		if result.addresses != nil {
+			addresses = result.addresses
+			break
+		}
+	}
+	if addresses == nil {
+		for _, result := range results {
+			fmt.Printf("❌ %s: %v\n", result.name, result.err)
+		}
+		return fmt.Errorf("所有网络均不可用")
+	}
+
+	// 钱包行：托管钱包在前，只读监控地址在后
+	rows := append([]common.Address{}, addresses...)
+	managed := make(map[common.Address]bool, len(addresses))
+	for _, address := range addresses {
+		managed[address] = true
+	}
+	for _, address := range watchAddresses {
+		if !managed[address] {
+			rows = append(rows, address)
+		}
+	}
+
+	// 按网络建立 地址 -> 余额 索引
+	balances := make([]map[common.Address]wallet.BalanceResult, len(results))
+	for i, result := range results {
+		if result.snapshot == nil {
+			continue
+		}
+		balances[i] = make(map[common.Address]wallet.BalanceResult, len(result.snapshot.Results))
+		for _, balance := range result.snapshot.Results {
+			balances[i][balance.Address] = balance
+		}
+	}
+
+	fmt.Printf("Wallet Balances on %d networks:\n\n", len(results))
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+
+	header := []string{"Wallet"}
+	blocks := []string{"Block"}
+	for _, result := range results {
+		header = append(header, fmt.Sprintf("%s (%s)", result.name, result.config.Symbol))
+		if result.snapshot != nil {
+			blocks = append(blocks, fmt.Sprintf("%d", result.snapshot.BlockNumber))
+		} else {
+			blocks = append(blocks, "-")
+		}
+	}
+	fmt.Fprintln(w, strings.Join(header, "\t")+"\t")
+	fmt.Fprintln(w, strings.Join(blocks, "\t")+"\t")
+
+	totals := make([]*big.Int, len(results))
+	for i := range totals {
+		totals[i] = big.NewInt(0)
+	}
+	for _, address := range rows {
+		name := book.Display(address.Hex())
+		if !managed[address] {
+			name += " [watch-only]"
+		}
+		cells := []string{name}
+		for i, result := range results {
+			if result.err != nil {
+				cells = append(cells, "不可用")
+				continue
+			}
+			balance := balances[i][address]
+			if balance.Err != nil || balance.Balance == nil {
+				cells = append(cells, "查询失败")
+				continue
+			}
+			cells = append(cells, wallet.FormatUnits(balance.Balance, result.config.Decimals))
+			if managed[address] {
+				totals[i].Add(totals[i], balance.Balance)
+			}
+		}
+		fmt.Fprintln(w, strings.Join(cells, "\t")+"\t")
+	}
+
+	totalCells := []string{"Total"}
+	for i, result := range results {
+		if result.err != nil {
+			totalCells = append(totalCells, "-")
+			continue
+		}
+		totalCells = append(totalCells, wallet.FormatUnits(totals[i], result.config.Decimals))
+	}
+	fmt.Fprintln(w, strings.Join(totalCells, "\t")+"\t")
+	w.Flush()
+
+	// 不可用的网络单独列出原因
+	var failed []string
+	for _, result := range results {
+		if result.err != nil {
+			failed = append(failed, fmt.Sprintf("   - %s: %v", result.name, result.err))
+		}
+	}
+	if len(failed) > 0 {
+		fmt.Printf("\n⚠️  %d 个网络不可用:\n%s\n", len(failed), strings.Join(failed, "\n"))
+	}
+
+	return nil
+}
+
+// queryNetworkBalances 连接单个网络并查询托管钱包和监控地址余额
+func queryNetworkBalances(c *cli.Context, envFile, name string, customRPCs map[string]string, watchAddresses []common.Address) networkBalances {
+	result := networkBalances{name: name}
+	result.config, _ = wallet.GetNetwork(name)
+
+	wm, err := wallet.NewManagerWithRPC(envFile, name, customRPCs)
+	if err != nil {
+		result.err = err
+		return result
+	}
+	defer wm.Close()
+	applyRPCOptions(c, wm)
+	result.config = wm.GetNetworkConfig()
+	result.addresses = wm.GetAddresses()
+
+	queryAddresses := append([]common.Address{}, result.addresses...)
+	queryAddresses = append(queryAddresses, watchAddresses...)
+	result.snapshot, result.err = wm.GetBalanceSnapshot(c.Context, queryAddresses, nil, nil)
+	return result
+}
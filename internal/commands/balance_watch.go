@@ -0,0 +1,115 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// maxWatchAlerts 监控模式下保留显示的最近告警条数
+const maxWatchAlerts = 10
+
+// watchBalances 持续监控余额：配置了WebSocket节点时订阅新区块，否则按间隔轮询
+// 每次刷新重绘表格并标注变化，余额跌破最低余额时告警，Ctrl-C 退出
+func watchBalances(ctx context.Context, view *balanceView, addresses []common.Address, interval time.Duration) error {
+	if interval <= 0 {
+		return fmt.Errorf("无效的刷新间隔: %s", interval)
+	}
+
+	heads := make(chan *types.Header, 16)
+	var subErr <-chan error
+	var tick <-chan time.Time
+	mode := "订阅新区块"
+
+	sub, err := view.wm.SubscribeNewHead(ctx, heads)
+	if err == nil {
+		defer sub.Unsubscribe()
+		subErr = sub.Err()
+	} else {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		tick = ticker.C
+		mode = fmt.Sprintf("每 %s 轮询", interval)
+	}
+
+	var previous map[common.Address]*big.Int
+	belowMin := make(map[common.Address]bool)
+	var alerts []string
+	var lastRefresh time.Time
+
+	refresh := func() {
+		snapshot, err := view.wm.GetBalanceSnapshot(ctx, addresses, view.token, nil)
+		if ctx.Err() != nil {
+			return
+		}
+		lastRefresh = time.Now()
+
+		// 清屏后重绘
+		fmt.Print("\033[H\033[2J")
+		fmt.Printf("👀 余额监控（%s，Ctrl-C 退出）  更新时间: %s\n", mode, lastRefresh.Format("15:04:05"))
+		if view.minBalance != nil {
+			fmt.Printf("   最低余额: %s %s\n", view.format(view.minBalance), view.symbol)
+		}
+		fmt.Println()
+		view.printHeader()
+
+		if err != nil {
+			fmt.Printf("❌ 查询失败: %v（保留上次结果，下次刷新时重试）\n", err)
+		} else {
+			fmt.Printf("Block: %d (%s)\n", snapshot.BlockNumber, snapshotSource(snapshot))
+			result := view.render(snapshot, previous)
+
+			// 只在跌破阈值时告警一次，恢复后重新计算
+			current := make(map[common.Address]bool, len(result.belowMin))
+			for _, address := range result.belowMin {
+				current[address] = true
+				if !belowMin[address] {
+					alerts = append(alerts, fmt.Sprintf("[%s] 🚨 %s 余额 %s %s 低于最低余额 %s %s",
+						lastRefresh.Format("15:04:05"), view.book.Display(address.Hex()),
+						view.format(result.balances[address]), view.symbol, view.format(view.minBalance), view.symbol))
+					fmt.Print("\a")
+				}
+			}
+			belowMin = current
+			previous = result.balances
+		}
+
+		if len(alerts) > maxWatchAlerts {
+			alerts = alerts[len(alerts)-maxWatchAlerts:]
+		}
+		if len(alerts) > 0 {
+			fmt.Printf("\n告警记录:\n")
+			for _, alert := range alerts {
+				fmt.Println(alert)
+			}
+		}
+	}
+
+	refresh()
+	for {
+		select {
+		case <-ctx.Done():
+			fmt.Println()
+			return nil
+		case <-tick:
+			refresh()
+		case <-heads:
+			// 出块较快的链上限制刷新频率
+			if time.Since(lastRefresh) >= time.Second {
+				refresh()
+			}
+		case err := <-subErr:
+			// 订阅中断时退回轮询
+			subErr = nil
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+			tick = ticker.C
+			mode = fmt.Sprintf("订阅中断(%v)，每 %s 轮询", err, interval)
+			refresh()
+		}
+	}
+}
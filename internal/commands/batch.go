@@ -1,26 +1,23 @@
 package commands
 
 import (
-	"bufio"
 	"context"
 	"fmt"
 	"math/big"
-	"os"
-	"strings"
 	"time"
 
 	"transfer-tool/internal/config"
 	"transfer-tool/internal/wallet"
 
+	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/urfave/cli/v2"
 )
 
 // BatchCommand 批量转账命令
 func BatchCommand(c *cli.Context) error {
 	configFile := c.String("config")
-	network := c.String("network")
+	network := wallet.NormalizeNetworkName(c.String("network"))
 	appConfig := config.LoadAppConfig()
 	envFile := appConfig.EnvFile
 
@@ -35,12 +32,21 @@ func BatchCommand(c *cli.Context) error {
 		return fmt.Errorf("加载配置文件失败: %v", err)
 	}
 
+	// 命令行指定的RPC优先级最高
+	if rpcURL := c.String("rpc"); rpcURL != "" {
+		if batchConfig.RPCConfig == nil {
+			batchConfig.RPCConfig = make(map[string]string)
+		}
+		batchConfig.RPCConfig[network] = rpcURL
+	}
+
 	// 创建钱包管理器（使用自定义RPC配置）
 	wm, err := wallet.NewManagerWithRPC(envFile, network, batchConfig.RPCConfig)
 	if err != nil {
 		return err
 	}
-	defer wm.GetClient().Close()
+	defer wm.Close()
+	applyRPCOptions(c, wm)
 
 	// 获取所有地址
 	addresses := wm.GetAddresses()
@@ -48,6 +54,51 @@ func BatchCommand(c *cli.Context) error {
 		return fmt.Errorf("没有可用的钱包地址")
 	}
 
+	// 加载地址簿
+	book, err := loadAddressBook(c)
+	if err != nil {
+		return err
+	}
+
+	gasOpts, err := wallet.ParseGasOptions(batchConfig.Gas.Strategy, batchConfig.Gas.MaxFeeGwei, batchConfig.Gas.PriorityFeeGwei)
+	if err != nil {
+		return fmt.Errorf("Gas配置无效: %v", err)
+	}
+	gate, err := newGasGate(batchConfig.Gas)
+	if err != nil {
+		return fmt.Errorf("Gas配置无效: %v", err)
+	}
+	replacer, err := newBatchReplacer(batchConfig.Gas)
+	if err != nil {
+		return fmt.Errorf("Gas配置无效: %v", err)
+	}
+
+	disperser, err := newBatchDisperser(batchConfig, wm.GetNetworkConfig())
+	if err != nil {
+		return fmt.Errorf("转账配置无效: %v", err)
+	}
+	if disperser != nil {
+		if err := disperser.checkContract(c.Context, wm); err != nil {
+			return err
+		}
+	}
+
+	// 代币批量转账通过 Disperse 合约执行
+	var token *wallet.TokenInfo
+	if tokenSpec := batchConfig.Transfer.TokenAddress; tokenSpec != "" {
+		if disperser == nil {
+			return fmt.Errorf("逐笔转账模式不支持代币，代币批量转账请设置 transfer.mode: %s", transferModeDisperse)
+		}
+		tokenAddr, err := book.Resolve(tokenSpec)
+		if err != nil {
+			return fmt.Errorf("无效的代币: %v", err)
+		}
+		token, err = wm.GetTokenInfo(c.Context, common.HexToAddress(tokenAddr))
+		if err != nil {
+			return err
+		}
+	}
+
 	// 加载接收方数据
 	recipients, err := config.LoadRecipients(batchConfig.DataSources.RecipientsXlsx)
 	if err != nil {
@@ -58,19 +109,39 @@ func BatchCommand(c *cli.Context) error {
 	fmt.Printf("   钱包数量: %d\n", len(addresses))
 	fmt.Printf("   接收方数量: %d\n", len(recipients))
 	fmt.Printf("   网络: %s\n", wm.GetNetworkConfig().Name)
+	if token != nil {
+		fmt.Printf("   代币: %s (%s)\n", token.Symbol, token.Address.Hex())
+	}
+	if disperser != nil {
+		fmt.Printf("   转账方式: %s\n", disperser)
+	}
+	fmt.Printf("   Gas策略: %s\n", gasOpts.Strategy)
+	if gate != nil {
+		fmt.Printf("   Gas门槛: %s\n", gate)
+	}
+	if replacer != nil {
+		fmt.Printf("   自动加速: %s\n", replacer)
+	}
 	fmt.Printf("   配置文件: %s\n", configFile)
+	fmt.Printf("   发送钱包:\n")
+	for _, address := range addresses {
+		fmt.Printf("   - %s\n", book.Display(address.Hex()))
+	}
 
 	// 确认执行
-	fmt.Printf("\n确认执行批量转账? (y/N): ")
-	reader := bufio.NewReader(os.Stdin)
-	confirm, _ := reader.ReadString('\n')
-	confirm = strings.TrimSpace(strings.ToLower(confirm))
-	if confirm != "y" && confirm != "yes" {
-		return fmt.Errorf("操作已取消")
+	if err := confirmYes(c.Context, "\n确认执行批量转账? (y/N): "); err != nil {
+		return err
 	}
+	fmt.Printf("   按 Ctrl-C 可中断：将不再开始新的转账，已发出的转账会执行完毕并生成报告\n")
 
-	// 执行批量转账
-	report, err := executeBatchTransfer(wm, recipients, batchConfig)
+	// 执行批量转账：本地解析接收方、金额，按轮询分配发送方
+	rows := prepareBatchRows(wm, recipients, token, book)
+	var report *config.BatchReport
+	if disperser != nil {
+		report, err = disperser.execute(c.Context, wm, rows, token, gasOpts, gate, replacer, book)
+	} else {
+		report, err = executeBatchTransfer(c.Context, wm, rows, gasOpts, gate, replacer, book)
+	}
 	if err != nil {
 		return fmt.Errorf("批量转账失败: %v", err)
 	}
@@ -88,8 +159,14 @@ func BatchCommand(c *cli.Context) error {
 	fmt.Printf("\n📊 批量转账完成:\n")
 	fmt.Printf("   成功: %d\n", report.Summary.Success)
 	fmt.Printf("   失败: %d\n", report.Summary.Failed)
+	if report.Summary.Skipped > 0 {
+		fmt.Printf("   跳过: %d\n", report.Summary.Skipped)
+	}
 	fmt.Printf("   总计: %d\n", report.Summary.Total)
 
+	if report.Interrupted {
+		return fmt.Errorf("批量转账已中断，剩余 %d 笔未执行，请查看报告详情", report.Summary.Skipped)
+	}
 	if report.Summary.Failed > 0 {
 		return fmt.Errorf("部分转账失败，请查看报告详情")
 	}
@@ -98,101 +175,269 @@ func BatchCommand(c *cli.Context) error {
 }
 
 // executeBatchTransfer 执行批量转账
-func executeBatchTransfer(wm *wallet.Manager, recipients []config.Recipient, batchConfig *config.BatchConfig) (*config.BatchReport, error) {
-	report := &config.BatchReport{
-		Timestamp: time.Now(),
-		Network:   wm.GetNetworkConfig().Name,
-		ChainID:   wm.GetChainID().String(),
-		Summary:   &config.BatchSummary{},
-		Details:   make([]*config.TransferDetail, 0, len(recipients)),
+// 收到中断信号或Gas等待超时后不再开始新的转账，剩余记录标记为跳过；正在执行的转账不受中断影响
+// 配置了自动加速时，全部发出后等待打包并加速长时间未打包的交易
+func executeBatchTransfer(ctx context.Context, wm *wallet.Manager, rows []*batchRow, gasOpts wallet.GasOptions, gate *gasGate, replacer *batchReplacer, book *config.AddressBook) (*config.BatchReport, error) {
+	report := newBatchReport(wm, book, len(rows))
+
+	// 预检：以JSON-RPC批量请求一次性查询发送钱包余额、nonce和每笔转账的Gas估算
+	fmt.Printf("\n🔍 预检中: %d 个发送钱包，%d 笔转账...\n", len(wm.GetAddresses()), len(rows))
+	state, err := preflightBatch(ctx, wm, rows, gasOpts, gate)
+	if err != nil {
+		return nil, fmt.Errorf("预检失败: %v", err)
+	}
+	if state.fees != nil {
+		printGasFees(state.fees)
 	}
 
-	addresses := wm.GetAddresses()
-	report.Summary.Total = len(recipients)
-
-	for i, recipient := range recipients {
-		// 轮询选择发送方
-		senderIndex := i % len(addresses)
-		fromAddress := addresses[senderIndex]
-		toAddress := common.HexToAddress(recipient.Address)
+	// 停止开始新的转账，剩余记录标记为跳过
+	stop := func(reason string) {
+		report.Interrupted = true
+		report.StopReason = reason
+		fmt.Printf("\n⚠️  %s，停止开始新的转账\n", reason)
+	}
 
-		// 转换金额
-		amount, err := wallet.ParseAmount(fmt.Sprintf("%.6f", recipient.Amount))
-		if err != nil {
-			report.AddFailedDetail(i, recipient, fmt.Sprintf("金额解析失败: %v", err))
+	for i, row := range rows {
+		if !report.Interrupted && ctx.Err() != nil {
+			stop("收到中断信号")
+		}
+		if report.Interrupted {
+			report.AddSkippedDetail(i, row.recipient, fmt.Sprintf("%s，未执行", report.StopReason))
 			continue
 		}
 
-		// 检查余额
-		balance, err := wm.GetBalance(fromAddress)
-		if err != nil {
-			report.AddFailedDetail(i, recipient, fmt.Sprintf("查询余额失败: %v", err))
+		if row.err != "" {
+			report.AddFailedDetail(i, row.recipient, row.err)
 			continue
 		}
 
-		// 估算Gas费用
-		gasPrice, err := wm.GetGasPrice()
-		if err != nil {
-			report.AddFailedDetail(i, recipient, fmt.Sprintf("获取Gas价格失败: %v", err))
+		// Gas价格门槛：高于阈值时暂停，回落后继续
+		if err := state.awaitGas(ctx, wm); err != nil {
+			if ctx.Err() != nil {
+				stop("收到中断信号")
+			} else {
+				stop(err.Error())
+			}
+			report.AddSkippedDetail(i, row.recipient, fmt.Sprintf("%s，未执行", report.StopReason))
 			continue
 		}
 
-		gasLimit, err := wm.EstimateGas(fromAddress, toAddress, amount, nil)
+		// 已开始的转账不随中断取消，仍受单次调用超时限制
+		rowCtx := context.WithoutCancel(ctx)
+
+		txHash, err := state.send(rowCtx, wm, row)
 		if err != nil {
-			report.AddFailedDetail(i, recipient, fmt.Sprintf("估算Gas失败: %v", err))
+			report.AddFailedDetail(i, row.recipient, err.Error())
 			continue
 		}
 
-		totalCost := new(big.Int).Add(amount, new(big.Int).Mul(gasPrice, big.NewInt(int64(gasLimit))))
+		// 记录成功
+		fromAddress := wm.GetAddressByIndex(row.senderIndex)
+		report.AddSuccessDetail(i, row.recipient, fromAddress.Hex(), txHash, wm.GetExplorerURL(txHash))
+		if replacer != nil {
+			replacer.markSent(rowCtx, wm, txHash)
+		}
+	}
+
+	if gate != nil {
+		report.GasWait = gate.waited
+	}
 
-		if balance.Cmp(totalCost) < 0 {
-			report.AddFailedDetail(i, recipient, fmt.Sprintf("余额不足: 需要 %s ETH，当前 %s ETH",
-				wallet.FormatAmount(totalCost), wallet.FormatAmount(balance)))
+	// 等待打包，超过区块数仍未打包的交易自动加速
+	if replacer != nil && ctx.Err() == nil {
+		replacer.awaitReceipts(ctx, wm, gasOpts, report)
+	}
+	return report, nil
+}
+
+// newBatchReport 创建批量转账报告，金额单位默认为原生代币
+func newBatchReport(wm *wallet.Manager, book *config.AddressBook, total int) *config.BatchReport {
+	return &config.BatchReport{
+		Timestamp: time.Now(),
+		Network:   wm.GetNetworkConfig().Name,
+		ChainID:   wm.GetChainID().String(),
+		Symbol:    wm.Symbol(),
+		Summary:   &config.BatchSummary{Total: total},
+		Details:   make([]*config.TransferDetail, 0, total),
+
+		AddressBook: book,
+	}
+}
+
+// batchRow 批量转账中的单笔转账
+type batchRow struct {
+	recipient   config.Recipient
+	senderIndex int
+	to          common.Address
+	amount      *big.Int
+	gasLimit    uint64
+	err         string // 预处理或预检阶段的失败原因
+}
+
+// prepareBatchRows 解析接收方（地址或地址簿标签）和金额，按轮询分配发送方
+// token 为空时金额按原生代币精度解析，否则按代币精度解析
+func prepareBatchRows(wm *wallet.Manager, recipients []config.Recipient, token *wallet.TokenInfo, book *config.AddressBook) []*batchRow {
+	addresses := wm.GetAddresses()
+	parse := wm.ParseNative
+	if token != nil {
+		parse = token.Parse
+	}
+	rows := make([]*batchRow, len(recipients))
+	for i, recipient := range recipients {
+		// 轮询选择发送方
+		row := &batchRow{recipient: recipient, senderIndex: i % len(addresses)}
+		rows[i] = row
+
+		recipientAddr, err := book.Resolve(recipient.Address)
+		if err != nil {
+			row.err = err.Error()
 			continue
 		}
+		row.recipient.Address = recipientAddr
+		row.to = common.HexToAddress(recipientAddr)
 
-		// 执行转账
-		txHash, err := executeSingleTransfer(wm, fromAddress, toAddress, amount, gasPrice, gasLimit)
+		row.amount, err = parse(fmt.Sprintf("%.6f", recipient.Amount))
 		if err != nil {
-			report.AddFailedDetail(i, recipient, fmt.Sprintf("转账失败: %v", err))
-			continue
+			row.err = fmt.Sprintf("金额解析失败: %v", err)
 		}
+	}
+	return rows
+}
 
-		// 记录成功
-		report.AddSuccessDetail(i, recipient, fromAddress.Hex(), txHash, wm.GetExplorerURL(txHash))
+// batchState 批量转账过程中本地维护的发送钱包余额、nonce和Gas费用，避免逐笔查询
+type batchState struct {
+	balances    []*big.Int
+	balanceErrs []error
+	nonces      []uint64
+	staleNonce  []bool
+	gasOpts     wallet.GasOptions
+	gate        *gasGate
+	fees        *wallet.GasFees
+	feesAt      time.Time
+}
+
+// gasFeesMaxAge Gas费用缓存时间，超过后按策略重新计算
+const gasFeesMaxAge = 30 * time.Second
+
+// preflightBatch 批量查询所有发送钱包的余额和nonce、当前Gas费用及每笔转账的Gas估算
+// 配置了Gas价格门槛时，费用查询失败（如超过网络上限）留到执行阶段等待
+func preflightBatch(ctx context.Context, wm *wallet.Manager, rows []*batchRow, gasOpts wallet.GasOptions, gate *gasGate) (*batchState, error) {
+	addresses := wm.GetAddresses()
+	state, err := newBatchState(ctx, wm, gasOpts, gate)
+	if err != nil {
+		return nil, err
 	}
 
-	return report, nil
+	// 批量估算Gas，仅估算预处理成功的转账
+	var msgs []ethereum.CallMsg
+	var pending []*batchRow
+	for _, row := range rows {
+		if row.err != "" {
+			continue
+		}
+		msgs = append(msgs, ethereum.CallMsg{
+			From:  addresses[row.senderIndex],
+			To:    &row.to,
+			Value: row.amount,
+		})
+		pending = append(pending, row)
+	}
+	estimates, err := wm.EstimateGasBatch(ctx, msgs)
+	if err != nil {
+		return nil, err
+	}
+	for i, estimate := range estimates {
+		if estimate.Err != nil {
+			pending[i].err = estimate.Err.Error()
+			continue
+		}
+		pending[i].gasLimit = estimate.Gas
+	}
+
+	return state, nil
 }
 
-// executeSingleTransfer 执行单笔转账
-func executeSingleTransfer(wm *wallet.Manager, from, to common.Address, value, gasPrice *big.Int, gasLimit uint64) (string, error) {
-	ctx := context.Background()
+// newBatchState 批量查询所有发送钱包的余额和nonce，以及当前Gas费用
+func newBatchState(ctx context.Context, wm *wallet.Manager, gasOpts wallet.GasOptions, gate *gasGate) (*batchState, error) {
+	addresses := wm.GetAddresses()
+	state := &batchState{
+		gasOpts:     gasOpts,
+		gate:        gate,
+		balances:    make([]*big.Int, len(addresses)),
+		balanceErrs: make([]error, len(addresses)),
+		staleNonce:  make([]bool, len(addresses)),
+	}
 
-	// 获取私钥
-	privateKey := wm.GetPrivateKeyByIndex(0)
+	balances, err := wm.GetBalances(ctx, addresses)
+	if err != nil {
+		return nil, err
+	}
+	for i, result := range balances {
+		state.balances[i], state.balanceErrs[i] = result.Balance, result.Err
+	}
 
-	// 获取nonce
-	nonce, err := wm.GetClient().PendingNonceAt(ctx, from)
+	state.nonces, err = wm.GetPendingNonces(ctx, addresses)
 	if err != nil {
-		return "", fmt.Errorf("获取nonce失败: %v", err)
+		return nil, err
 	}
 
-	// 构建交易
-	tx := types.NewTransaction(nonce, to, value, gasLimit, gasPrice, nil)
+	if err := state.refreshFees(ctx, wm); err != nil && gate == nil {
+		return nil, err
+	}
+	return state, nil
+}
 
-	// 签名交易
-	chainID := wm.GetChainID()
-	signedTx, err := types.SignTx(tx, types.NewEIP155Signer(chainID), privateKey)
+// refreshFees Gas费用过期时按策略重新计算
+func (s *batchState) refreshFees(ctx context.Context, wm *wallet.Manager) error {
+	if s.fees != nil && time.Since(s.feesAt) <= gasFeesMaxAge {
+		return nil
+	}
+	fees, err := wm.SuggestFees(ctx, s.gasOpts)
 	if err != nil {
-		return "", fmt.Errorf("签名交易失败: %v", err)
+		return err
+	}
+	s.fees, s.feesAt = fees, time.Now()
+	return nil
+}
+
+// send 按本地维护的余额和nonce发送单笔转账，成功后更新本地状态
+func (s *batchState) send(ctx context.Context, wm *wallet.Manager, row *batchRow) (string, error) {
+	return s.sendTx(ctx, wm, row.senderIndex, row.to, row.amount, nil, row.gasLimit)
+}
+
+// sendTx 按本地维护的余额和nonce发送交易（转账或合约调用），成功后更新本地状态
+func (s *batchState) sendTx(ctx context.Context, wm *wallet.Manager, sender int, to common.Address, value *big.Int, data []byte, gasLimit uint64) (string, error) {
+	if s.balanceErrs[sender] != nil {
+		return "", s.balanceErrs[sender]
+	}
+
+	if err := s.refreshFees(ctx, wm); err != nil {
+		return "", err
+	}
+
+	totalCost := new(big.Int).Add(value, s.fees.MaxCost(gasLimit))
+	if s.balances[sender].Cmp(totalCost) < 0 {
+		return "", fmt.Errorf("余额不足: 需要 %s %s，当前 %s %s",
+			wm.FormatNative(totalCost), wm.Symbol(), wm.FormatNative(s.balances[sender]), wm.Symbol())
+	}
+
+	// 上一笔发送失败时nonce可能已变化，重新查询
+	if s.staleNonce[sender] {
+		nonces, err := wm.GetPendingNonces(ctx, []common.Address{wm.GetAddressByIndex(sender)})
+		if err != nil {
+			return "", err
+		}
+		s.nonces[sender], s.staleNonce[sender] = nonces[0], false
 	}
 
-	// 发送交易
-	err = wm.GetClient().SendTransaction(ctx, signedTx)
+	txHash, err := wm.SendTxWithNonce(ctx, sender, &to, value, data, s.fees, gasLimit, s.nonces[sender])
 	if err != nil {
-		return "", fmt.Errorf("发送交易失败: %v", err)
+		s.staleNonce[sender] = true
+		return "", err
 	}
 
-	return signedTx.Hash().Hex(), nil
+	// 按最大Gas消耗扣减，实际剩余余额只会更多
+	s.nonces[sender]++
+	s.balances[sender].Sub(s.balances[sender], totalCost)
+	return txHash, nil
 }
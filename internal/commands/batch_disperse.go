@@ -0,0 +1,351 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"transfer-tool/internal/config"
+	"transfer-tool/internal/wallet"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// 批量转账方式
+const (
+	transferModeSequential = "sequential"
+	transferModeDisperse   = "disperse"
+)
+
+// defaultDisperseChunkSize disperse 模式每笔交易默认包含的接收方数量
+const defaultDisperseChunkSize = 100
+
+// batchDisperser 批量转账的 disperse 模式：通过 Disperse 合约每批接收方只发送一笔交易
+type batchDisperser struct {
+	contract  common.Address
+	chunkSize int
+}
+
+// newBatchDisperser 根据批量配置创建 disperse 模式设置，逐笔转账模式返回空
+func newBatchDisperser(cfg *config.BatchConfig, network wallet.NetworkConfig) (*batchDisperser, error) {
+	switch cfg.Transfer.Mode {
+	case "", transferModeSequential:
+		return nil, nil
+	case transferModeDisperse:
+	default:
+		return nil, fmt.Errorf("无效的 mode: %s（可选 %s、%s）", cfg.Transfer.Mode, transferModeSequential, transferModeDisperse)
+	}
+	if cfg.Transfer.ChunkSize < 0 {
+		return nil, fmt.Errorf("无效的 chunk_size: %d", cfg.Transfer.ChunkSize)
+	}
+
+	disperser := &batchDisperser{
+		contract:  network.DisperseAddress,
+		chunkSize: cfg.Transfer.ChunkSize,
+	}
+	if disperser.chunkSize == 0 {
+		disperser.chunkSize = defaultDisperseChunkSize
+	}
+	return disperser, nil
+}
+
+// String disperse 模式说明，用于执行前展示
+func (d *batchDisperser) String() string {
+	return fmt.Sprintf("Disperse 合约 %s，每笔交易最多 %d 个接收方", d.contract.Hex(), d.chunkSize)
+}
+
+// checkContract 检查当前网络上是否部署了 Disperse 合约
+func (d *batchDisperser) checkContract(ctx context.Context, wm *wallet.Manager) error {
+	isContract, err := wm.IsContract(ctx, d.contract)
+	if err != nil {
+		return err
+	}
+	if !isContract {
+		return fmt.Errorf("地址 %s 上没有 Disperse 合约，请在 configs/config.yaml 的 networks.%s.disperse_address 中配置本网络的合约地址",
+			d.contract.Hex(), wm.GetNetwork())
+	}
+	return nil
+}
+
+// disperseChunk 一笔 disperse 交易包含的转账
+type disperseChunk struct {
+	sender  int
+	indexes []int // 各转账在批量转账中的序号
+	rows    []*batchRow
+	total   *big.Int
+}
+
+// split 将预处理成功的转账按 chunk_size 分批，按轮询为每批分配发送方
+func (d *batchDisperser) split(wm *wallet.Manager, rows []*batchRow) []*disperseChunk {
+	wallets := len(wm.GetAddresses())
+	var chunks []*disperseChunk
+	var current *disperseChunk
+	for i, row := range rows {
+		if row.err != "" {
+			continue
+		}
+		if current == nil || len(current.rows) == d.chunkSize {
+			current = &disperseChunk{sender: len(chunks) % wallets, total: big.NewInt(0)}
+			chunks = append(chunks, current)
+		}
+		current.indexes = append(current.indexes, i)
+		current.rows = append(current.rows, row)
+		current.total.Add(current.total, row.amount)
+	}
+	return chunks
+}
+
+// disperseTokenState 代币 disperse 过程中本地维护的发送钱包代币余额和授权状态
+type disperseTokenState struct {
+	token       *wallet.TokenInfo
+	balances    []*big.Int
+	balanceErrs []error
+	required    []*big.Int // 每个发送钱包全部批次的代币合计，按此额度授权
+	approved    []bool
+}
+
+// newDisperseTokenState 查询发送钱包的代币余额，并汇总每个钱包需要授权的额度
+func newDisperseTokenState(ctx context.Context, wm *wallet.Manager, token *wallet.TokenInfo, chunks []*disperseChunk) (*disperseTokenState, error) {
+	addresses := wm.GetAddresses()
+	snapshot, err := wm.GetBalanceSnapshot(ctx, addresses, token, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	tokens := &disperseTokenState{
+		token:       token,
+		balances:    make([]*big.Int, len(addresses)),
+		balanceErrs: make([]error, len(addresses)),
+		required:    make([]*big.Int, len(addresses)),
+		approved:    make([]bool, len(addresses)),
+	}
+	for i, result := range snapshot.Results {
+		tokens.balances[i], tokens.balanceErrs[i] = result.Balance, result.Err
+		tokens.required[i] = big.NewInt(0)
+	}
+	for _, chunk := range chunks {
+		tokens.required[chunk.sender].Add(tokens.required[chunk.sender], chunk.total)
+	}
+	return tokens, nil
+}
+
+// checkBalance 检查发送钱包的代币余额是否足够支付本批合计
+func (t *disperseTokenState) checkBalance(chunk *disperseChunk) error {
+	if err := t.balanceErrs[chunk.sender]; err != nil {
+		return err
+	}
+	if t.balances[chunk.sender].Cmp(chunk.total) < 0 {
+		return fmt.Errorf("余额不足: 需要 %s %s，当前 %s %s",
+			t.token.Format(chunk.total), t.token.Symbol, t.token.Format(t.balances[chunk.sender]), t.token.Symbol)
+	}
+	return nil
+}
+
+// execute 以 disperse 模式执行批量转账：每批接收方由发送钱包发出一笔 disperseEther / disperseToken 交易
+// 同一批的转账在报告中共用交易哈希，发送失败时整批记为失败；中断、Gas门槛和自动加速的处理与逐笔转账相同
+func (d *batchDisperser) execute(ctx context.Context, wm *wallet.Manager, rows []*batchRow, token *wallet.TokenInfo, gasOpts wallet.GasOptions, gate *gasGate, replacer *batchReplacer, book *config.AddressBook) (*config.BatchReport, error) {
+	report := newBatchReport(wm, book, len(rows))
+	report.Disperse = d.contract.Hex()
+	format := wm.FormatNative
+	if token != nil {
+		report.Symbol = token.Symbol
+		format = token.Format
+	}
+
+	for i, row := range rows {
+		if row.err != "" {
+			report.AddFailedDetail(i, row.recipient, row.err)
+		}
+	}
+	chunks := d.split(wm, rows)
+
+	// 预检：批量查询发送钱包余额、nonce，代币转账还需查询代币余额
+	fmt.Printf("\n🔍 预检中: %d 个发送钱包，%d 个接收方分为 %d 笔交易...\n",
+		len(wm.GetAddresses()), len(rows)-report.Summary.Failed, len(chunks))
+	state, err := newBatchState(ctx, wm, gasOpts, gate)
+	if err != nil {
+		return nil, fmt.Errorf("预检失败: %v", err)
+	}
+	if state.fees != nil {
+		printGasFees(state.fees)
+	}
+	var tokens *disperseTokenState
+	if token != nil {
+		if tokens, err = newDisperseTokenState(ctx, wm, token, chunks); err != nil {
+			return nil, fmt.Errorf("预检失败: %v", err)
+		}
+	}
+
+	// 停止开始新的交易，剩余批次标记为跳过
+	stop := func(reason string) {
+		report.Interrupted = true
+		report.StopReason = reason
+		fmt.Printf("\n⚠️  %s，停止开始新的转账\n", reason)
+	}
+	skip := func(chunk *disperseChunk) {
+		for j, row := range chunk.rows {
+			report.AddSkippedDetail(chunk.indexes[j], row.recipient, fmt.Sprintf("%s，未执行", report.StopReason))
+		}
+	}
+
+	for n, chunk := range chunks {
+		if !report.Interrupted && ctx.Err() != nil {
+			stop("收到中断信号")
+		}
+		if report.Interrupted {
+			skip(chunk)
+			continue
+		}
+
+		// Gas价格门槛：高于阈值时暂停，回落后继续
+		if err := state.awaitGas(ctx, wm); err != nil {
+			if ctx.Err() != nil {
+				stop("收到中断信号")
+			} else {
+				stop(err.Error())
+			}
+			skip(chunk)
+			continue
+		}
+
+		txHash, err := d.send(ctx, wm, state, tokens, chunk)
+		if err != nil {
+			fmt.Printf("❌ 第 %d/%d 批（%d 个接收方）发送失败: %v\n", n+1, len(chunks), len(chunk.rows), err)
+			for j, row := range chunk.rows {
+				report.AddFailedDetail(chunk.indexes[j], row.recipient, err.Error())
+			}
+			continue
+		}
+
+		report.DisperseTxs++
+		if replacer != nil {
+			replacer.markSent(context.WithoutCancel(ctx), wm, txHash)
+		}
+		fromAddress := wm.GetAddressByIndex(chunk.sender)
+		for j, row := range chunk.rows {
+			report.AddSuccessDetail(chunk.indexes[j], row.recipient, fromAddress.Hex(), txHash, wm.GetExplorerURL(txHash))
+		}
+		fmt.Printf("📤 第 %d/%d 批已发送: %d 个接收方，合计 %s %s，交易 %s\n",
+			n+1, len(chunks), len(chunk.rows), format(chunk.total), report.Symbol, txHash)
+	}
+
+	// 预处理失败的记录先于各批次加入，按序号排列
+	sort.SliceStable(report.Details, func(i, j int) bool {
+		return report.Details[i].Index < report.Details[j].Index
+	})
+
+	if gate != nil {
+		report.GasWait = gate.waited
+	}
+
+	// 等待打包，超过区块数仍未打包的交易自动加速
+	if replacer != nil && ctx.Err() == nil {
+		replacer.awaitReceipts(ctx, wm, gasOpts, report)
+	}
+	return report, nil
+}
+
+// send 发送一批转账：代币授权不足时先授权并等待打包，再估算Gas并发出 disperse 交易
+// ctx 仅用于等待授权打包，已开始的交易发送不随中断取消
+func (d *batchDisperser) send(ctx context.Context, wm *wallet.Manager, state *batchState, tokens *disperseTokenState, chunk *disperseChunk) (string, error) {
+	sendCtx := context.WithoutCancel(ctx)
+
+	recipients := make([]common.Address, len(chunk.rows))
+	values := make([]*big.Int, len(chunk.rows))
+	for i, row := range chunk.rows {
+		recipients[i], values[i] = row.to, row.amount
+	}
+
+	// 原生代币随交易转入合约，代币由合约通过 transferFrom 转出
+	var token *wallet.TokenInfo
+	value := chunk.total
+	if tokens != nil {
+		token, value = tokens.token, big.NewInt(0)
+		if err := tokens.checkBalance(chunk); err != nil {
+			return "", err
+		}
+		if err := d.approve(ctx, wm, state, tokens, chunk.sender); err != nil {
+			return "", err
+		}
+	} else if balance := state.balances[chunk.sender]; balance != nil && balance.Cmp(value) < 0 {
+		// 余额不足时估算Gas会失败，提前给出明确的提示
+		return "", fmt.Errorf("余额不足: 需要 %s %s，当前 %s %s",
+			wm.FormatNative(value), wm.Symbol(), wm.FormatNative(balance), wm.Symbol())
+	}
+
+	data, err := wallet.PackDisperse(token, recipients, values)
+	if err != nil {
+		return "", err
+	}
+	gasLimit, err := wm.EstimateGas(sendCtx, wm.GetAddressByIndex(chunk.sender), d.contract, value, data)
+	if err != nil {
+		return "", err
+	}
+	txHash, err := state.sendTx(sendCtx, wm, chunk.sender, d.contract, value, data, gasLimit)
+	if err != nil {
+		return "", err
+	}
+
+	if tokens != nil {
+		tokens.balances[chunk.sender].Sub(tokens.balances[chunk.sender], chunk.total)
+	}
+	return txHash, nil
+}
+
+// approve 发送钱包对 Disperse 合约的授权额度不足其全部批次合计时重新授权，并等待授权交易打包
+// 已有非零授权时先清零，兼容要求先清零再授权的代币（如 USDT）
+func (d *batchDisperser) approve(ctx context.Context, wm *wallet.Manager, state *batchState, tokens *disperseTokenState, sender int) error {
+	if tokens.approved[sender] {
+		return nil
+	}
+	allowance, err := wm.GetAllowance(ctx, tokens.token, wm.GetAddressByIndex(sender), d.contract)
+	if err != nil {
+		return err
+	}
+
+	required := tokens.required[sender]
+	if allowance.Cmp(required) < 0 {
+		amounts := []*big.Int{required}
+		if allowance.Sign() > 0 {
+			amounts = []*big.Int{big.NewInt(0), required}
+		}
+		for _, amount := range amounts {
+			if err := d.sendApprove(ctx, wm, state, tokens.token, sender, amount); err != nil {
+				return err
+			}
+		}
+	}
+	tokens.approved[sender] = true
+	return nil
+}
+
+// sendApprove 发送一笔授权交易并等待打包
+func (d *batchDisperser) sendApprove(ctx context.Context, wm *wallet.Manager, state *batchState, token *wallet.TokenInfo, sender int, amount *big.Int) error {
+	sendCtx := context.WithoutCancel(ctx)
+	owner := wm.GetAddressByIndex(sender)
+
+	data, err := wallet.ERC20ABI.Pack("approve", d.contract, amount)
+	if err != nil {
+		return fmt.Errorf("编码授权交易失败: %v", err)
+	}
+	gasLimit, err := wm.EstimateGas(sendCtx, owner, token.Address, big.NewInt(0), data)
+	if err != nil {
+		return fmt.Errorf("授权失败: %v", err)
+	}
+	txHash, err := state.sendTx(sendCtx, wm, sender, token.Address, big.NewInt(0), data, gasLimit)
+	if err != nil {
+		return fmt.Errorf("授权失败: %v", err)
+	}
+
+	fmt.Printf("🔑 %s 授权 Disperse 合约使用 %s %s，等待打包: %s\n", owner.Hex(), token.Format(amount), token.Symbol, txHash)
+	receipt, err := wm.WaitReceipt(ctx, common.HexToHash(txHash))
+	if err != nil {
+		return fmt.Errorf("授权交易未确认: %v", err)
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		return fmt.Errorf("授权交易执行失败: %s", txHash)
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"transfer-tool/internal/config"
+	"transfer-tool/internal/wallet"
+)
+
+// 默认的Gas价格门槛等待参数
+const (
+	defaultGasMaxWait      = time.Hour
+	defaultGasPollInterval = 30 * time.Second
+)
+
+// gasGate 批量转账的Gas价格门槛：base fee（传统网络为 gasPrice）高于阈值时暂停，回落后自动继续
+type gasGate struct {
+	maxBaseFee   *big.Int
+	maxWait      time.Duration // 整个批量任务累计的最长等待时间
+	pollInterval time.Duration
+	waited       time.Duration
+}
+
+// newGasGate 根据批量配置创建Gas价格门槛，未配置 max_base_fee_gwei 时返回空
+func newGasGate(cfg config.GasConfig) (*gasGate, error) {
+	if strings.TrimSpace(cfg.MaxBaseFeeGwei) == "" {
+		return nil, nil
+	}
+
+	maxBaseFee, err := wallet.ParseUnits(cfg.MaxBaseFeeGwei, 9)
+	if err != nil {
+		return nil, fmt.Errorf("无效的 max_base_fee_gwei: %v", err)
+	}
+	gate := &gasGate{
+		maxBaseFee:   maxBaseFee,
+		maxWait:      defaultGasMaxWait,
+		pollInterval: defaultGasPollInterval,
+	}
+
+	if cfg.MaxWait != "" {
+		if gate.maxWait, err = time.ParseDuration(cfg.MaxWait); err != nil || gate.maxWait <= 0 {
+			return nil, fmt.Errorf("无效的 max_wait: %s（示例: 30m, 2h）", cfg.MaxWait)
+		}
+	}
+	if cfg.PollInterval != "" {
+		if gate.pollInterval, err = time.ParseDuration(cfg.PollInterval); err != nil || gate.pollInterval <= 0 {
+			return nil, fmt.Errorf("无效的 poll_interval: %s（示例: 15s, 1m）", cfg.PollInterval)
+		}
+	}
+	return gate, nil
+}
+
+// String 门槛说明，用于执行前展示
+func (g *gasGate) String() string {
+	return fmt.Sprintf("base fee ≤ %s Gwei（最长等待 %s，每 %s 查询）",
+		wallet.FormatGwei(g.maxBaseFee), g.maxWait, g.pollInterval)
+}
+
+// level 用于比较门槛的费用：EIP-1559 网络为 base fee，传统网络为 gasPrice
+func (g *gasGate) level(fees *wallet.GasFees) *big.Int {
+	if fees.Dynamic() {
+		return fees.BaseFee
+	}
+	return fees.GasPrice
+}
+
+// allows 当前费用是否低于门槛
+func (g *gasGate) allows(fees *wallet.GasFees) bool {
+	return fees != nil && g.level(fees).Cmp(g.maxBaseFee) <= 0
+}
+
+// awaitGas 开始每笔转账前检查Gas价格门槛，高于阈值时暂停并按间隔重新查询，直到回落、超过最长等待时间或被中断
+// 查询费用遇到瞬时错误（超时、限流等）时继续等待，其他错误（如节点不支持、配置错误）直接返回
+func (s *batchState) awaitGas(ctx context.Context, wm *wallet.Manager) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	gate := s.gate
+	if gate == nil {
+		return nil
+	}
+
+	err := s.refreshFees(ctx, wm)
+	if err != nil && !wallet.IsTransientError(err) {
+		return err
+	}
+	if err == nil && gate.allows(s.fees) {
+		return nil
+	}
+
+	fmt.Println()
+	for {
+		// 显示等待进度，同一行刷新
+		if err != nil {
+			fmt.Printf("\r⏸  等待Gas回落: 查询费用失败(%v)，已等待 %s / 最长 %s   ",
+				err, gate.waited.Round(time.Second), gate.maxWait)
+		} else {
+			fmt.Printf("\r⏸  等待Gas回落: 当前 %s Gwei > 阈值 %s Gwei，已等待 %s / 最长 %s   ",
+				wallet.FormatGwei(gate.level(s.fees)), wallet.FormatGwei(gate.maxBaseFee),
+				gate.waited.Round(time.Second), gate.maxWait)
+		}
+
+		remaining := gate.maxWait - gate.waited
+		if remaining <= 0 {
+			fmt.Println()
+			return fmt.Errorf("Gas价格持续高于阈值 %s Gwei，累计等待已达 %s", wallet.FormatGwei(gate.maxBaseFee), gate.maxWait)
+		}
+		interval := gate.pollInterval
+		if interval > remaining {
+			interval = remaining
+		}
+
+		start := time.Now()
+		select {
+		case <-ctx.Done():
+			gate.waited += time.Since(start)
+			fmt.Println()
+			return ctx.Err()
+		case <-time.After(interval):
+			gate.waited += time.Since(start)
+		}
+
+		var fees *wallet.GasFees
+		fees, err = wm.SuggestFees(ctx, s.gasOpts)
+		if err != nil {
+			if !wallet.IsTransientError(err) {
+				fmt.Println()
+				return err
+			}
+			continue
+		}
+		s.fees, s.feesAt = fees, time.Now()
+		if gate.allows(fees) {
+			fmt.Printf("\n▶️  Gas已回落至 %s Gwei，继续执行\n", wallet.FormatGwei(gate.level(fees)))
+			return nil
+		}
+	}
+}
@@ -0,0 +1,272 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"transfer-tool/internal/config"
+	"transfer-tool/internal/wallet"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// 默认的自动加速参数
+const (
+	defaultMaxReplacements   = 3
+	replacementPollInterval  = 5 * time.Second
+	replacementMaxQueryError = 10 // 连续查询失败次数上限，超过后停止等待
+)
+
+// batchReplacer 批量转账的自动加速：发出后超过指定区块数仍未打包的交易以更高费用重新广播
+type batchReplacer struct {
+	afterBlocks     uint64
+	maxReplacements int
+
+	sentBlocks map[string]uint64 // 交易哈希 → 发送时的最新区块
+	head       uint64
+	headAt     time.Time
+}
+
+// newBatchReplacer 根据批量配置创建自动加速设置，未配置 replace_after_blocks 时返回空
+func newBatchReplacer(cfg config.GasConfig) (*batchReplacer, error) {
+	if cfg.ReplaceAfterBlocks == 0 {
+		return nil, nil
+	}
+	if cfg.ReplaceAfterBlocks < 0 {
+		return nil, fmt.Errorf("无效的 replace_after_blocks: %d", cfg.ReplaceAfterBlocks)
+	}
+	if cfg.MaxReplacements < 0 {
+		return nil, fmt.Errorf("无效的 max_replacements: %d", cfg.MaxReplacements)
+	}
+
+	replacer := &batchReplacer{
+		afterBlocks:     uint64(cfg.ReplaceAfterBlocks),
+		maxReplacements: cfg.MaxReplacements,
+		sentBlocks:      make(map[string]uint64),
+	}
+	if replacer.maxReplacements == 0 {
+		replacer.maxReplacements = defaultMaxReplacements
+	}
+	return replacer, nil
+}
+
+// String 自动加速说明，用于执行前展示
+func (r *batchReplacer) String() string {
+	return fmt.Sprintf("超过 %d 个区块未打包自动加速（每笔最多 %d 次）", r.afterBlocks, r.maxReplacements)
+}
+
+// headMaxAge 记录发送区块时最新区块高度的缓存时间，短于出块间隔
+const headMaxAge = time.Second
+
+// markSent 记录交易发送时的最新区块，作为计算未打包区块数的起点
+// 查询失败时不记录，开始等待时以当时的最新区块为起点
+func (r *batchReplacer) markSent(ctx context.Context, wm *wallet.Manager, txHash string) {
+	if time.Since(r.headAt) > headMaxAge {
+		header, err := wm.HeaderByNumber(ctx, nil)
+		if err != nil {
+			return
+		}
+		r.head, r.headAt = header.Number.Uint64(), time.Now()
+	}
+	r.sentBlocks[txHash] = r.head
+}
+
+// pendingTransfer 等待打包的批量转账交易，disperse 模式下一笔交易对应多条转账记录
+type pendingTransfer struct {
+	details   []*config.TransferDetail
+	hashes    []common.Hash // 原交易及各次替换交易，最后一个为最新广播的交易
+	sentBlock uint64
+}
+
+// name 提示信息中的交易名称
+func (p *pendingTransfer) name() string {
+	if len(p.details) == 1 {
+		return fmt.Sprintf("第 %d 笔交易", p.details[0].Index+1)
+	}
+	return fmt.Sprintf("第 %d 笔起 %d 个接收方的交易", p.details[0].Index+1, len(p.details))
+}
+
+// latest 最新广播的交易哈希
+func (p *pendingTransfer) latest() common.Hash {
+	return p.hashes[len(p.hashes)-1]
+}
+
+// awaitReceipts 等待已发出的转账打包，超过区块数（从发送时起算）仍未打包的交易按Gas策略加速
+// 每笔交易加速次数用完或收到中断信号后停止等待，报告中记录最终打包（或最新广播）的交易哈希，
+// 打包后执行失败的交易对应的记录改为失败
+func (r *batchReplacer) awaitReceipts(ctx context.Context, wm *wallet.Manager, gasOpts wallet.GasOptions, report *config.BatchReport) {
+	header, err := wm.HeaderByNumber(ctx, nil)
+	if err != nil {
+		fmt.Printf("⚠️  获取最新区块失败，跳过等待打包: %v\n", err)
+		return
+	}
+
+	var pending []*pendingTransfer
+	byHash := make(map[string]*pendingTransfer)
+	for _, detail := range report.Details {
+		if detail.Status != "success" {
+			continue
+		}
+		if p, ok := byHash[detail.TxHash]; ok {
+			p.details = append(p.details, detail)
+			continue
+		}
+		p := &pendingTransfer{
+			details:   []*config.TransferDetail{detail},
+			hashes:    []common.Hash{common.HexToHash(detail.TxHash)},
+			sentBlock: header.Number.Uint64(),
+		}
+		if block, ok := r.sentBlocks[detail.TxHash]; ok {
+			p.sentBlock = block
+		}
+		byHash[detail.TxHash] = p
+		pending = append(pending, p)
+	}
+	if len(pending) == 0 {
+		return
+	}
+
+	fmt.Printf("\n⏳ 等待 %d 笔交易打包，%s，按 Ctrl-C 停止等待\n", len(pending), r)
+
+	var mined, reverted, abandoned, queryErrors int
+	for len(pending) > 0 {
+		select {
+		case <-ctx.Done():
+			fmt.Printf("\n⚠️  收到中断信号，停止等待，%d 笔交易仍未打包\n", len(pending))
+			return
+		case <-time.After(replacementPollInterval):
+		}
+
+		header, err := wm.HeaderByNumber(ctx, nil)
+		var receipts []*types.Receipt
+		if err == nil {
+			var hashes []common.Hash
+			for _, p := range pending {
+				hashes = append(hashes, p.hashes...)
+			}
+			receipts, err = wm.GetReceipts(ctx, hashes)
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				continue
+			}
+			queryErrors++
+			if queryErrors >= replacementMaxQueryError {
+				fmt.Printf("⚠️  连续 %d 次查询失败，停止等待，%d 笔交易仍未打包: %v\n", queryErrors, len(pending), err)
+				return
+			}
+			continue
+		}
+		queryErrors = 0
+		block := header.Number.Uint64()
+
+		var still []*pendingTransfer
+		offset := 0
+		for _, p := range pending {
+			receipt, minedHash := findReceipt(receipts[offset:offset+len(p.hashes)], p.hashes)
+			offset += len(p.hashes)
+
+			if receipt != nil {
+				p.setTxHash(wm, minedHash)
+				mined++
+				if receipt.Status == types.ReceiptStatusFailed {
+					reverted++
+					message := describeRevert(ctx, wm, minedHash)
+					fmt.Printf("⚠️  %s%s\n", p.name(), message)
+					for _, detail := range p.details {
+						report.MarkFailed(detail, message)
+					}
+				}
+				continue
+			}
+
+			if block < p.sentBlock+r.afterBlocks {
+				still = append(still, p)
+				continue
+			}
+			if len(p.hashes)-1 >= r.maxReplacements {
+				fmt.Printf("⚠️  %s已加速 %d 次仍未打包，停止等待: %s\n",
+					p.name(), r.maxReplacements, p.latest().Hex())
+				abandoned++
+				continue
+			}
+
+			// 交易可能刚好被打包，替换失败时保留，下一轮重新查询收据
+			if err := r.replace(ctx, wm, gasOpts, p, block); err != nil {
+				fmt.Printf("⚠️  %s加速失败: %v\n", p.name(), err)
+			}
+			still = append(still, p)
+		}
+		pending = still
+	}
+
+	switch {
+	case abandoned > 0:
+		fmt.Printf("⚠️  %d 笔交易已打包，%d 笔加速后仍未打包，可稍后使用 tx speedup / tx cancel 处理\n", mined, abandoned)
+	case reverted > 0:
+		fmt.Printf("⚠️  %d 笔交易已打包，其中 %d 笔执行失败\n", mined, reverted)
+	default:
+		fmt.Printf("✅ %d 笔交易已全部打包\n", mined)
+	}
+}
+
+// replace 以更高费用重新广播交易，成功后记录被替换的交易哈希
+func (r *batchReplacer) replace(ctx context.Context, wm *wallet.Manager, gasOpts wallet.GasOptions, p *pendingTransfer, block uint64) error {
+	replacement, err := wm.PrepareReplacement(ctx, p.latest(), false, gasOpts)
+	if err != nil {
+		return err
+	}
+	if err := wm.BroadcastTransaction(ctx, replacement.Tx); err != nil {
+		return err
+	}
+
+	old := p.latest()
+	p.hashes = append(p.hashes, replacement.Tx.Hash())
+	p.sentBlock = block
+	p.setTxHash(wm, p.latest())
+	fmt.Printf("🔁 %s %d 个区块未打包，已加速（第 %d 次，%s）: %s → %s\n",
+		p.name(), r.afterBlocks, len(p.hashes)-1, describeTxFees(replacement.Tx),
+		old.Hex()[:10]+"...", replacement.Tx.Hash().Hex())
+	return nil
+}
+
+// setTxHash 报告记录已打包（或最新广播）的交易，其余为被替换的交易
+func (p *pendingTransfer) setTxHash(wm *wallet.Manager, hash common.Hash) {
+	var replaced []string
+	for _, h := range p.hashes {
+		if h != hash {
+			replaced = append(replaced, h.Hex())
+		}
+	}
+	for _, detail := range p.details {
+		detail.TxHash = hash.Hex()
+		detail.Explorer = wm.GetExplorerURL(detail.TxHash)
+		detail.Replaced = replaced
+	}
+}
+
+// describeRevert 已打包但执行失败的交易说明，尽量附带回滚原因
+func describeRevert(ctx context.Context, wm *wallet.Manager, hash common.Hash) string {
+	message := fmt.Sprintf("已打包但执行失败: %s", hash.Hex())
+	info, err := wm.GetTxInfo(ctx, hash)
+	if err != nil {
+		return message
+	}
+	reason, err := wm.RevertReason(ctx, info)
+	if err != nil {
+		return message
+	}
+	return fmt.Sprintf("%s（%s）", message, reason)
+}
+
+// findReceipt 返回已打包的收据及对应的交易哈希，均未打包时返回空
+func findReceipt(receipts []*types.Receipt, hashes []common.Hash) (*types.Receipt, common.Hash) {
+	for i, receipt := range receipts {
+		if receipt != nil {
+			return receipt, hashes[i]
+		}
+	}
+	return nil, common.Hash{}
+}
@@ -0,0 +1,177 @@
+package commands
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"transfer-tool/internal/config"
+	"transfer-tool/internal/wallet"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/urfave/cli/v2"
+)
+
+// newWalletManager 按全局参数创建钱包管理器
+func newWalletManager(c *cli.Context) (*wallet.Manager, error) {
+	network := wallet.NormalizeNetworkName(c.String("network"))
+	appConfig := config.LoadAppConfig()
+	envFile := appConfig.EnvFile
+
+	// 尝试加载全局RPC配置
+	var customRPCs map[string]string
+	if globalRPC, err := config.LoadGlobalRPCConfig(); err == nil {
+		customRPCs = globalRPC
+	}
+
+	// 命令行指定的RPC优先级最高
+	if rpcURL := c.String("rpc"); rpcURL != "" {
+		if customRPCs == nil {
+			customRPCs = make(map[string]string)
+		}
+		customRPCs[network] = rpcURL
+	}
+
+	wm, err := wallet.NewManagerWithRPC(envFile, network, customRPCs)
+	if err != nil {
+		return nil, err
+	}
+	applyRPCOptions(c, wm)
+	return wm, nil
+}
+
+// newKeyManager 只加载私钥的钱包管理器，离线签名不需要连接RPC节点
+func newKeyManager() (*wallet.Manager, error) {
+	return wallet.NewKeyManager(config.LoadAppConfig().EnvFile)
+}
+
+// applyRPCOptions 应用全局RPC参数：单次调用超时和限流（未指定 --rps 时使用网络配置 rpc_rps）
+func applyRPCOptions(c *cli.Context, wm *wallet.Manager) {
+	wm.SetCallTimeout(c.Duration("timeout"))
+	if c.IsSet("rps") {
+		wm.SetRateLimit(c.Float64("rps"))
+	}
+}
+
+// promptLine 输出提示并读取一行输入，收到中断信号时立即返回
+func promptLine(ctx context.Context, prompt string) (string, error) {
+	fmt.Print(prompt)
+
+	lines := make(chan string, 1)
+	go func() {
+		line, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+		lines <- strings.TrimSpace(line)
+	}()
+
+	select {
+	case <-ctx.Done():
+		fmt.Println()
+		return "", fmt.Errorf("操作已取消")
+	case line := <-lines:
+		return line, nil
+	}
+}
+
+// confirmYes 询问 y/N 确认，未确认时返回取消错误
+func confirmYes(ctx context.Context, prompt string) error {
+	answer, err := promptLine(ctx, prompt)
+	if err != nil {
+		return err
+	}
+	answer = strings.ToLower(answer)
+	if answer != "y" && answer != "yes" {
+		return fmt.Errorf("操作已取消")
+	}
+	return nil
+}
+
+// confirmTransaction 发送交易前确认：主网显示警告并要求输入 MAINNET，其他网络询问 y/N
+// skip 为 true（--yes）时不询问，主网警告仍会显示；action 用于警告文字，如 "执行转账操作"
+func confirmTransaction(ctx context.Context, wm *wallet.Manager, skip bool, action, prompt string) error {
+	if wm.GetNetworkConfig().IsMainnet() {
+		fmt.Printf("\n⚠️  警告: 您正在主网%s！\n", action)
+		if skip {
+			return nil
+		}
+		confirm, err := promptLine(ctx, "请输入 'MAINNET' 确认: ")
+		if err != nil {
+			return err
+		}
+		if confirm != "MAINNET" {
+			return fmt.Errorf("操作已取消")
+		}
+		return nil
+	}
+	if skip {
+		return nil
+	}
+	return confirmYes(ctx, prompt)
+}
+
+// printGasFees 显示交易费用参数
+func printGasFees(fees *wallet.GasFees) {
+	fmt.Printf("   Gas策略: %s\n", fees.Strategy)
+	if fees.Dynamic() {
+		fmt.Printf("   Base Fee: %s Gwei\n", wallet.FormatGwei(fees.BaseFee))
+		fmt.Printf("   Max Fee: %s Gwei\n", wallet.FormatGwei(fees.MaxFeePerGas))
+		fmt.Printf("   Priority Fee: %s Gwei\n", wallet.FormatGwei(fees.MaxPriorityFeePerGas))
+	} else {
+		fmt.Printf("   Gas价格: %s Gwei\n", wallet.FormatGwei(fees.GasPrice))
+	}
+	if fees.Capped {
+		fmt.Printf("   ⚠️  费用已按网络上限 max_fee_gwei 截断，Gas上涨时交易可能延迟打包\n")
+	}
+}
+
+// loadAddressBook 按全局参数加载地址簿
+func loadAddressBook(c *cli.Context) (*config.AddressBook, error) {
+	path := c.String("address-book")
+	if path == "" {
+		path = config.LoadAppConfig().AddressBookFile
+	}
+	return config.LoadAddressBook(path)
+}
+
+// resolveWallet 将钱包索引、地址或地址簿标签解析为托管钱包索引
+func resolveWallet(wm *wallet.Manager, book *config.AddressBook, spec string) (int, error) {
+	if _, err := strconv.Atoi(spec); err == nil || common.IsHexAddress(spec) {
+		return wm.FindWallet(spec)
+	}
+
+	address, err := book.Resolve(spec)
+	if err != nil {
+		return 0, err
+	}
+	index, err := wm.FindWallet(address)
+	if err != nil {
+		return 0, fmt.Errorf("标签 %s 对应的地址不是托管钱包: %s", spec, address)
+	}
+	return index, nil
+}
+
+// timestampLayouts 支持的时间格式，不含时区的按本地时间解析
+var timestampLayouts = []string{
+	time.RFC3339,
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+// parseTimestamp 解析Unix时间戳（秒）或日期时间字符串
+func parseTimestamp(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Unix(seconds, 0), nil
+	}
+	for _, layout := range timestampLayouts {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("无效的时间: %s（支持Unix时间戳、2006-01-02、2006-01-02 15:04:05 或 RFC3339）", value)
+}
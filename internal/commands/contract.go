@@ -0,0 +1,517 @@
+package commands
+
+import (
+	"fmt"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"transfer-tool/internal/config"
+	"transfer-tool/internal/wallet"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/urfave/cli/v2"
+)
+
+// contractCall 解析后的合约调用
+type contractCall struct {
+	to      common.Address
+	method  *abi.Method   // 使用 --data 时为空
+	args    []interface{} // 按ABI类型转换后的参数
+	data    []byte
+	fromABI bool // 方法来自ABI文件，可检查只读和 payable 属性
+}
+
+// CallCommand 只读调用合约（eth_call），解码并显示返回值
+func CallCommand(c *cli.Context) error {
+	const usage = "transfer-tool call [--abi <file>] <contract|label> <function> [args...] 或 call --data <hex> <contract|label>"
+	ctx := c.Context
+
+	book, err := loadAddressBook(c)
+	if err != nil {
+		return err
+	}
+	call, err := parseContractCall(c, book, usage)
+	if err != nil {
+		return err
+	}
+
+	wm, err := newWalletManager(c)
+	if err != nil {
+		return err
+	}
+	defer wm.Close()
+
+	// 调用方：指定的地址，默认第一个托管钱包
+	msg := ethereum.CallMsg{To: &call.to, Data: call.data}
+	if spec := c.String("from"); spec != "" {
+		if msg.From, err = resolveCaller(wm, book, spec); err != nil {
+			return fmt.Errorf("无效的调用方: %v", err)
+		}
+	} else if addresses := wm.GetAddresses(); len(addresses) > 0 {
+		msg.From = addresses[0]
+	}
+	if value := c.String("value"); value != "" {
+		if msg.Value, err = wm.ParseNative(value); err != nil {
+			return fmt.Errorf("无效的金额: %v", err)
+		}
+	}
+	var block *big.Int
+	if c.IsSet("block") {
+		block = new(big.Int).SetUint64(c.Uint64("block"))
+	}
+
+	fmt.Printf("📞 合约调用（只读）:\n")
+	printContractCall(wm, book, call, msg.From)
+	if block != nil {
+		fmt.Printf("   区块: %s\n", block.String())
+	}
+
+	result, err := wm.CallContract(ctx, msg, block)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("\n📦 返回值:\n")
+	if call.method == nil || len(call.method.Outputs) == 0 {
+		fmt.Printf("   原始数据: %s\n", hexutil.Encode(result))
+		if call.method != nil && len(result) > 0 {
+			fmt.Printf("   在签名后附加返回类型（如 balanceOf(address)(uint256)）或使用 --abi 可解码返回值\n")
+		}
+		return nil
+	}
+	values, err := call.method.Outputs.Unpack(result)
+	if err != nil {
+		return fmt.Errorf("解码返回值失败: %v（原始数据 %s）", err, hexutil.Encode(result))
+	}
+	for i, output := range call.method.Outputs {
+		fmt.Printf("   %s: %s\n", describeABIArgument(i, output), formatABIValue(output.Type, values[i], book))
+	}
+	return nil
+}
+
+// ExecCommand 使用托管钱包发送合约调用交易
+func ExecCommand(c *cli.Context) error {
+	const usage = "transfer-tool exec [--abi <file>] [--value <amount>] <contract|label> <function> [args...] 或 exec --data <hex> <contract|label>"
+	ctx := c.Context
+
+	book, err := loadAddressBook(c)
+	if err != nil {
+		return err
+	}
+	call, err := parseContractCall(c, book, usage)
+	if err != nil {
+		return err
+	}
+	gasOpts, err := wallet.ParseGasOptions(c.String("gas"), c.String("max-fee"), c.String("priority-fee"))
+	if err != nil {
+		return err
+	}
+
+	wm, err := newWalletManager(c)
+	if err != nil {
+		return err
+	}
+	defer wm.Close()
+
+	if len(wm.GetAddresses()) == 0 {
+		return fmt.Errorf("没有可用的钱包地址")
+	}
+	var senderIndex int
+	if spec := c.String("from"); spec != "" {
+		if senderIndex, err = resolveWallet(wm, book, spec); err != nil {
+			return fmt.Errorf("无效的发送钱包: %v", err)
+		}
+	}
+	fromAddress := wm.GetAddressByIndex(senderIndex)
+
+	value := big.NewInt(0)
+	if amount := c.String("value"); amount != "" {
+		if value, err = wm.ParseNative(amount); err != nil {
+			return fmt.Errorf("无效的金额: %v", err)
+		}
+	}
+
+	// ABI中标明的只读方法不需要发送交易，非 payable 方法不能附带金额
+	if call.fromABI {
+		if call.method.IsConstant() {
+			return fmt.Errorf("方法 %s 为只读（%s），不会修改链上状态，请使用 call 命令", call.method.Sig, call.method.StateMutability)
+		}
+		if value.Sign() > 0 && !call.method.IsPayable() {
+			return fmt.Errorf("方法 %s 不是 payable，不能附带 --value", call.method.Sig)
+		}
+	}
+
+	isContract, err := wm.IsContract(ctx, call.to)
+	if err != nil {
+		return err
+	}
+	if !isContract && call.method != nil {
+		return fmt.Errorf("地址 %s 上没有合约，请检查合约地址和网络", call.to.Hex())
+	}
+
+	// 先模拟执行，回滚时直接显示原因
+	msg := ethereum.CallMsg{From: fromAddress, To: &call.to, Value: value, Data: call.data}
+	if _, err := wm.CallContract(ctx, msg, nil); err != nil {
+		return fmt.Errorf("模拟执行失败: %v", err)
+	}
+
+	balance, err := wm.GetBalance(ctx, fromAddress)
+	if err != nil {
+		return fmt.Errorf("查询余额失败: %v", err)
+	}
+	fees, err := wm.SuggestFees(ctx, gasOpts)
+	if err != nil {
+		return err
+	}
+	gasLimit, err := wm.EstimateGas(ctx, fromAddress, call.to, value, call.data)
+	if err != nil {
+		return err
+	}
+	totalCost := new(big.Int).Add(value, fees.MaxCost(gasLimit))
+	if balance.Cmp(totalCost) < 0 {
+		return fmt.Errorf("余额不足: 需要 %s %s，当前余额 %s %s",
+			wm.FormatNative(totalCost), wm.Symbol(), wm.FormatNative(balance), wm.Symbol())
+	}
+
+	fmt.Printf("📝 合约交易:\n")
+	printContractCall(wm, book, call, fromAddress)
+	if !isContract {
+		fmt.Printf("   ⚠️  目标地址不是合约，调用数据将随转账发送\n")
+	}
+	if value.Sign() > 0 {
+		fmt.Printf("   金额: %s %s\n", wm.FormatNative(value), wm.Symbol())
+	}
+	printGasFees(fees)
+	fmt.Printf("   Gas限制: %d\n", gasLimit)
+	fmt.Printf("   最高手续费: %s %s\n", wm.FormatNative(fees.MaxCost(gasLimit)), wm.Symbol())
+
+	// 主网需输入 MAINNET 确认，其他网络询问 y/N
+	if err := confirmTransaction(ctx, wm, c.Bool("yes"), "发送合约交易", "\n确认发送交易? (y/N): "); err != nil {
+		return err
+	}
+
+	nonces, err := wm.GetPendingNonces(ctx, []common.Address{fromAddress})
+	if err != nil {
+		return err
+	}
+	txHash, err := wm.SendTxWithNonce(ctx, senderIndex, &call.to, value, call.data, fees, gasLimit, nonces[0])
+	if err != nil {
+		return fmt.Errorf("发送失败: %v", err)
+	}
+
+	fmt.Printf("\n✅ 交易已发送!\n")
+	fmt.Printf("   交易哈希: %s\n", txHash)
+	if explorerURL := wm.GetExplorerURL(txHash); explorerURL != "" {
+		fmt.Printf("   区块浏览器: %s\n", explorerURL)
+	}
+	fmt.Printf("   可使用 tx status %s 查询执行结果\n", txHash)
+	return nil
+}
+
+// parseContractCall 解析合约地址和函数（签名，或 --abi 文件中的方法名）及参数，或 --data 指定的原始调用数据
+func parseContractCall(c *cli.Context, book *config.AddressBook, usage string) (*contractCall, error) {
+	if c.NArg() < 1 {
+		return nil, fmt.Errorf("用法: %s", usage)
+	}
+	target, err := book.Resolve(c.Args().First())
+	if err != nil {
+		return nil, err
+	}
+	call := &contractCall{to: common.HexToAddress(target)}
+	args := c.Args().Slice()[1:]
+
+	if raw := c.String("data"); raw != "" {
+		if len(args) > 0 || c.String("abi") != "" {
+			return nil, fmt.Errorf("--data 不能与函数、参数或 --abi 同时使用")
+		}
+		if !strings.HasPrefix(raw, "0x") && !strings.HasPrefix(raw, "0X") {
+			raw = "0x" + raw
+		}
+		if call.data, err = hexutil.Decode(raw); err != nil {
+			return nil, fmt.Errorf("无效的调用数据: %v", err)
+		}
+		return call, nil
+	}
+	if len(args) == 0 {
+		return nil, fmt.Errorf("用法: %s", usage)
+	}
+
+	if abiFile := c.String("abi"); abiFile != "" {
+		parsed, err := wallet.LoadABIFile(abiFile)
+		if err != nil {
+			return nil, err
+		}
+		if call.method, err = wallet.FindMethod(parsed, args[0]); err != nil {
+			return nil, err
+		}
+		call.fromABI = true
+	} else if call.method, err = wallet.ParseMethodSignature(args[0]); err != nil {
+		return nil, err
+	}
+
+	if call.args, err = parseABIArgs(call.method.Inputs, args[1:], book); err != nil {
+		return nil, fmt.Errorf("%s: %v", call.method.Sig, err)
+	}
+	packed, err := call.method.Inputs.Pack(call.args...)
+	if err != nil {
+		return nil, fmt.Errorf("编码参数失败: %v", err)
+	}
+	call.data = append(append([]byte{}, call.method.ID...), packed...)
+	return call, nil
+}
+
+// resolveCaller 解析只读调用的调用方：托管钱包索引，或任意地址、地址簿标签
+func resolveCaller(wm *wallet.Manager, book *config.AddressBook, spec string) (common.Address, error) {
+	if _, err := strconv.Atoi(spec); err == nil {
+		index, err := wm.FindWallet(spec)
+		if err != nil {
+			return common.Address{}, err
+		}
+		return wm.GetAddressByIndex(index), nil
+	}
+	address, err := book.Resolve(spec)
+	if err != nil {
+		return common.Address{}, err
+	}
+	return common.HexToAddress(address), nil
+}
+
+// printContractCall 显示调用目标、方法和参数
+func printContractCall(wm *wallet.Manager, book *config.AddressBook, call *contractCall, from common.Address) {
+	fmt.Printf("   调用方: %s\n", book.Display(from.Hex()))
+	fmt.Printf("   合约: %s\n", book.Display(call.to.Hex()))
+	if call.method == nil {
+		fmt.Printf("   调用数据: %d 字节，%s\n", len(call.data), describeSelector(call.data))
+	} else {
+		fmt.Printf("   方法: %s（选择器 %s）\n", call.method.Sig, hexutil.Encode(call.method.ID))
+		for i, input := range call.method.Inputs {
+			fmt.Printf("   - %s: %s\n", describeABIArgument(i, input), formatABIValue(input.Type, call.args[i], book))
+		}
+	}
+	fmt.Printf("   网络: %s\n", wm.GetNetworkConfig().Name)
+}
+
+// describeABIArgument 参数说明：有名称时为 "名称 (类型)"，否则为 "#序号 (类型)"
+func describeABIArgument(index int, arg abi.Argument) string {
+	name := arg.Name
+	if name == "" || isGeneratedName(name) {
+		name = fmt.Sprintf("#%d", index+1)
+	}
+	return fmt.Sprintf("%s (%s)", name, arg.Type.String())
+}
+
+// isGeneratedName 解析函数签名时自动生成的参数名（name0、name1…）
+func isGeneratedName(name string) bool {
+	if !strings.HasPrefix(name, "name") {
+		return false
+	}
+	_, err := strconv.Atoi(strings.TrimPrefix(name, "name"))
+	return err == nil
+}
+
+// parseABIArgs 按ABI类型转换命令行参数
+func parseABIArgs(inputs abi.Arguments, values []string, book *config.AddressBook) ([]interface{}, error) {
+	if len(values) != len(inputs) {
+		return nil, fmt.Errorf("需要 %d 个参数，实际 %d 个", len(inputs), len(values))
+	}
+	args := make([]interface{}, len(inputs))
+	for i, input := range inputs {
+		value, err := parseABIValue(input.Type, values[i], book)
+		if err != nil {
+			return nil, fmt.Errorf("第 %d 个参数 (%s) 无效: %v", i+1, input.Type.String(), err)
+		}
+		args[i] = value
+	}
+	return args, nil
+}
+
+// parseABIValue 将字符串转换为ABI类型对应的Go值
+// 地址支持地址簿标签；整数支持十进制和0x十六进制；数组写作 [a,b]，结构体写作 (a,b)
+func parseABIValue(t abi.Type, s string, book *config.AddressBook) (interface{}, error) {
+	s = strings.TrimSpace(s)
+	switch t.T {
+	case abi.AddressTy:
+		address, err := book.Resolve(s)
+		if err != nil {
+			return nil, err
+		}
+		return common.HexToAddress(address), nil
+
+	case abi.BoolTy:
+		return strconv.ParseBool(s)
+
+	case abi.StringTy:
+		return unquoteABIString(s), nil
+
+	case abi.IntTy, abi.UintTy:
+		n, ok := new(big.Int).SetString(s, 0)
+		if !ok {
+			return nil, fmt.Errorf("无效的整数: %s", s)
+		}
+		if err := checkIntRange(t, n); err != nil {
+			return nil, err
+		}
+		// 只有 8/16/32/64 位整数对应Go原生整数类型，其余位数（如 uint24、uint256）使用 *big.Int
+		if t.GetType().Kind() == reflect.Ptr {
+			return n, nil
+		}
+		value := reflect.New(t.GetType()).Elem()
+		if t.T == abi.UintTy {
+			value.SetUint(n.Uint64())
+		} else {
+			value.SetInt(n.Int64())
+		}
+		return value.Interface(), nil
+
+	case abi.BytesTy:
+		return hexutil.Decode(s)
+
+	case abi.FixedBytesTy:
+		data, err := hexutil.Decode(s)
+		if err != nil {
+			return nil, err
+		}
+		if len(data) != t.Size {
+			return nil, fmt.Errorf("需要 %d 字节，实际 %d 字节", t.Size, len(data))
+		}
+		value := reflect.New(t.GetType()).Elem()
+		reflect.Copy(value, reflect.ValueOf(data))
+		return value.Interface(), nil
+
+	case abi.SliceTy, abi.ArrayTy:
+		items, err := splitABIList(s, '[', ']')
+		if err != nil {
+			return nil, err
+		}
+		var value reflect.Value
+		if t.T == abi.ArrayTy {
+			if len(items) != t.Size {
+				return nil, fmt.Errorf("需要 %d 个元素，实际 %d 个", t.Size, len(items))
+			}
+			value = reflect.New(t.GetType()).Elem()
+		} else {
+			value = reflect.MakeSlice(t.GetType(), len(items), len(items))
+		}
+		for i, item := range items {
+			elem, err := parseABIValue(*t.Elem, item, book)
+			if err != nil {
+				return nil, fmt.Errorf("第 %d 个元素: %v", i+1, err)
+			}
+			value.Index(i).Set(reflect.ValueOf(elem))
+		}
+		return value.Interface(), nil
+
+	case abi.TupleTy:
+		items, err := splitABIList(s, '(', ')')
+		if err != nil {
+			return nil, err
+		}
+		if len(items) != len(t.TupleElems) {
+			return nil, fmt.Errorf("需要 %d 个字段，实际 %d 个", len(t.TupleElems), len(items))
+		}
+		value := reflect.New(t.GetType()).Elem()
+		for i, item := range items {
+			field, err := parseABIValue(*t.TupleElems[i], item, book)
+			if err != nil {
+				return nil, fmt.Errorf("第 %d 个字段: %v", i+1, err)
+			}
+			value.Field(i).Set(reflect.ValueOf(field))
+		}
+		return value.Interface(), nil
+	}
+	return nil, fmt.Errorf("不支持的参数类型 %s", t.String())
+}
+
+// checkIntRange 检查整数是否在ABI类型的取值范围内
+func checkIntRange(t abi.Type, n *big.Int) error {
+	if t.T == abi.UintTy {
+		if n.Sign() < 0 || n.BitLen() > t.Size {
+			return fmt.Errorf("超出 %s 的取值范围: %s", t.String(), n.String())
+		}
+		return nil
+	}
+	limit := new(big.Int).Lsh(big.NewInt(1), uint(t.Size-1))
+	if n.Cmp(limit) >= 0 || n.Cmp(new(big.Int).Neg(limit)) < 0 {
+		return fmt.Errorf("超出 %s 的取值范围: %s", t.String(), n.String())
+	}
+	return nil
+}
+
+// splitABIList 拆分数组 [a,b] 或结构体 (a,b) 的顶层元素，嵌套的括号和双引号内的逗号不拆分
+func splitABIList(s string, open, close byte) ([]string, error) {
+	if len(s) < 2 || s[0] != open || s[len(s)-1] != close {
+		return nil, fmt.Errorf("格式应为 %c...%c: %s", open, close, s)
+	}
+	inner := strings.TrimSpace(s[1 : len(s)-1])
+	if inner == "" {
+		return nil, nil
+	}
+
+	var items []string
+	depth, start, quoted := 0, 0, false
+	for i := 0; i < len(inner); i++ {
+		switch ch := inner[i]; {
+		case ch == '"' && (i == 0 || inner[i-1] != '\\'):
+			quoted = !quoted
+		case quoted:
+		case ch == '[' || ch == '(':
+			depth++
+		case ch == ']' || ch == ')':
+			depth--
+		case ch == ',' && depth == 0:
+			items = append(items, strings.TrimSpace(inner[start:i]))
+			start = i + 1
+		}
+	}
+	if depth != 0 || quoted {
+		return nil, fmt.Errorf("括号或引号不匹配: %s", s)
+	}
+	return append(items, strings.TrimSpace(inner[start:])), nil
+}
+
+// unquoteABIString 去掉字符串参数两端的双引号（数组元素中包含逗号时需要引号）
+func unquoteABIString(s string) string {
+	if len(s) >= 2 && s[0] == '"' && s[len(s)-1] == '"' {
+		if unquoted, err := strconv.Unquote(s); err == nil {
+			return unquoted
+		}
+		return s[1 : len(s)-1]
+	}
+	return s
+}
+
+// formatABIValue 按ABI类型格式化值：地址附带地址簿标签，字节为十六进制，数组写作 [a, b]，结构体写作 (a, b)
+// 需要按ABI类型而不是Go类型判断：uint8[] 与 bytes 在Go中都是字节切片
+func formatABIValue(t abi.Type, value interface{}, book *config.AddressBook) string {
+	rv := reflect.ValueOf(value)
+	switch t.T {
+	case abi.AddressTy:
+		return book.Display(value.(common.Address).Hex())
+	case abi.StringTy:
+		return strconv.Quote(value.(string))
+	case abi.BytesTy:
+		return hexutil.Encode(value.([]byte))
+	case abi.FixedBytesTy:
+		data := make([]byte, rv.Len())
+		reflect.Copy(reflect.ValueOf(data), rv)
+		return hexutil.Encode(data)
+	case abi.SliceTy, abi.ArrayTy:
+		items := make([]string, rv.Len())
+		for i := range items {
+			items[i] = formatABIValue(*t.Elem, rv.Index(i).Interface(), book)
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	case abi.TupleTy:
+		items := make([]string, len(t.TupleElems))
+		for i := range items {
+			items[i] = formatABIValue(*t.TupleElems[i], rv.Field(i).Interface(), book)
+		}
+		return "(" + strings.Join(items, ", ") + ")"
+	}
+	return fmt.Sprint(value)
+}
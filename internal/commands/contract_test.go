@@ -0,0 +1,235 @@
+package commands
+
+import (
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"transfer-tool/internal/config"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const testBookAddress = "0x8ba1f109551bD432803012645Ac136ddd64DBA72"
+
+func newTestAddressBook(t *testing.T) *config.AddressBook {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "addressbook.yaml")
+	content := "addresses:\n  - address: \"" + testBookAddress + "\"\n    label: \"treasury\"\n"
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	book, err := config.LoadAddressBook(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return book
+}
+
+func mustABIType(t *testing.T, name string, components []abi.ArgumentMarshaling) abi.Type {
+	t.Helper()
+	typ, err := abi.NewType(name, "", components)
+	if err != nil {
+		t.Fatalf("abi.NewType(%s): %v", name, err)
+	}
+	return typ
+}
+
+// TestParseABIIntSizes 覆盖所有整数位数：边界值可解析、可编码，超出范围报错
+func TestParseABIIntSizes(t *testing.T) {
+	book := newTestAddressBook(t)
+	one := big.NewInt(1)
+	for size := 8; size <= 256; size += 8 {
+		uintMax := new(big.Int).Sub(new(big.Int).Lsh(one, uint(size)), one)
+		intMax := new(big.Int).Sub(new(big.Int).Lsh(one, uint(size-1)), one)
+		intMin := new(big.Int).Neg(new(big.Int).Lsh(one, uint(size-1)))
+
+		tests := []struct {
+			typ     string
+			value   *big.Int
+			wantErr bool
+		}{
+			{fmt.Sprintf("uint%d", size), big.NewInt(0), false},
+			{fmt.Sprintf("uint%d", size), uintMax, false},
+			{fmt.Sprintf("uint%d", size), new(big.Int).Add(uintMax, one), true},
+			{fmt.Sprintf("uint%d", size), big.NewInt(-1), true},
+			{fmt.Sprintf("int%d", size), intMax, false},
+			{fmt.Sprintf("int%d", size), intMin, false},
+			{fmt.Sprintf("int%d", size), new(big.Int).Add(intMax, one), true},
+			{fmt.Sprintf("int%d", size), new(big.Int).Sub(intMin, one), true},
+		}
+		for _, tt := range tests {
+			typ := mustABIType(t, tt.typ, nil)
+			value, err := parseABIValue(typ, tt.value.String(), book)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("%s %s: 期望超出范围错误", tt.typ, tt.value)
+				}
+				continue
+			}
+			if err != nil {
+				t.Errorf("%s %s: %v", tt.typ, tt.value, err)
+				continue
+			}
+
+			// 解析结果必须能按该类型编码，解码后数值不变
+			args := abi.Arguments{{Type: typ}}
+			packed, err := args.Pack(value)
+			if err != nil {
+				t.Errorf("%s %s: 编码失败: %v", tt.typ, tt.value, err)
+				continue
+			}
+			unpacked, err := args.Unpack(packed)
+			if err != nil {
+				t.Errorf("%s %s: 解码失败: %v", tt.typ, tt.value, err)
+				continue
+			}
+			if got := formatABIValue(typ, unpacked[0], book); got != tt.value.String() {
+				t.Errorf("%s: 往返后为 %s，期望 %s", tt.typ, got, tt.value)
+			}
+		}
+	}
+}
+
+func TestParseABIValue(t *testing.T) {
+	book := newTestAddressBook(t)
+	tests := []struct {
+		typ        string
+		components []abi.ArgumentMarshaling
+		input      string
+		want       string // formatABIValue 的结果
+		wantErr    bool
+	}{
+		{typ: "uint24", input: "3000", want: "3000"},
+		{typ: "uint256", input: "0xff", want: "255"},
+		{typ: "uint256", input: "1_000_000", want: "1000000"},
+		{typ: "uint8", input: "abc", wantErr: true},
+		{typ: "int24", input: "-887272", want: "-887272"},
+		{typ: "bool", input: "true", want: "true"},
+		{typ: "bool", input: "yes", wantErr: true},
+		{typ: "address", input: "treasury", want: testBookAddress + " (treasury)"},
+		{typ: "address", input: "unknown", wantErr: true},
+		{typ: "string", input: "hello", want: `"hello"`},
+		{typ: "string", input: `"a,b"`, want: `"a,b"`},
+		{typ: "bytes", input: "0x0102", want: "0x0102"},
+		{typ: "bytes4", input: "0x12345678", want: "0x12345678"},
+		{typ: "bytes4", input: "0x1234", wantErr: true},
+		{typ: "uint24[]", input: "[500, 3000,10000]", want: "[500, 3000, 10000]"},
+		{typ: "uint256[]", input: "[]", want: "[]"},
+		{typ: "uint8[2]", input: "[1,2]", want: "[1, 2]"},
+		{typ: "uint8[2]", input: "[1]", wantErr: true},
+		{typ: "uint8[]", input: "[1,2]", want: "[1, 2]"},
+		{typ: "uint8[]", input: "1,2", wantErr: true},
+		{typ: "string[]", input: `["a,b", c]`, want: `["a,b", "c"]`},
+		{typ: "uint256[][]", input: "[[1,2],[3]]", want: "[[1, 2], [3]]"},
+		{
+			typ: "tuple",
+			components: []abi.ArgumentMarshaling{
+				{Name: "recipient", Type: "address"},
+				{Name: "fee", Type: "uint24"},
+				{Name: "amounts", Type: "uint256[]"},
+			},
+			input: "(treasury, 3000, [1,2])",
+			want:  "(" + testBookAddress + " (treasury), 3000, [1, 2])",
+		},
+		{
+			typ:        "tuple",
+			components: []abi.ArgumentMarshaling{{Name: "a", Type: "uint8"}, {Name: "b", Type: "bool"}},
+			input:      "(1)",
+			wantErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		typ := mustABIType(t, tt.typ, tt.components)
+		value, err := parseABIValue(typ, tt.input, book)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("%s %q: 期望报错，得到 %v", tt.typ, tt.input, value)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s %q: %v", tt.typ, tt.input, err)
+			continue
+		}
+		if _, err := (abi.Arguments{{Type: typ}}).Pack(value); err != nil {
+			t.Errorf("%s %q: 编码失败: %v", tt.typ, tt.input, err)
+		}
+		if got := formatABIValue(typ, value, book); got != tt.want {
+			t.Errorf("%s %q: 得到 %s，期望 %s", tt.typ, tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestFormatABIValue(t *testing.T) {
+	book := newTestAddressBook(t)
+	other := common.HexToAddress("0x0000000000000000000000000000000000000001")
+	tests := []struct {
+		typ        string
+		components []abi.ArgumentMarshaling
+		value      interface{}
+		want       string
+	}{
+		{typ: "int256", value: big.NewInt(-5), want: "-5"},
+		{typ: "uint8", value: uint8(7), want: "7"},
+		{typ: "bool", value: true, want: "true"},
+		{typ: "address", value: other, want: other.Hex()},
+		{typ: "address", value: common.HexToAddress(testBookAddress), want: testBookAddress + " (treasury)"},
+		{typ: "bytes32", value: [32]byte{1}, want: "0x0100000000000000000000000000000000000000000000000000000000000000"},
+		{typ: "bytes", value: []byte{0xab}, want: "0xab"},
+		{typ: "uint8[]", value: []uint8{1, 2}, want: "[1, 2]"},
+		{typ: "uint8[2]", value: [2]uint8{1, 2}, want: "[1, 2]"},
+		{typ: "address[]", value: []common.Address{other}, want: "[" + other.Hex() + "]"},
+		{
+			typ:        "tuple",
+			components: []abi.ArgumentMarshaling{{Name: "a", Type: "uint256"}, {Name: "b", Type: "string"}},
+			value: struct {
+				A *big.Int `json:"a"`
+				B string   `json:"b"`
+			}{big.NewInt(1), "x"},
+			want: `(1, "x")`,
+		},
+	}
+	for _, tt := range tests {
+		typ := mustABIType(t, tt.typ, tt.components)
+		if got := formatABIValue(typ, tt.value, book); got != tt.want {
+			t.Errorf("formatABIValue(%s, %#v) = %s，期望 %s", tt.typ, tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestSplitABIList(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    []string
+		wantErr bool
+	}{
+		{input: "[]", want: nil},
+		{input: "[a]", want: []string{"a"}},
+		{input: "[a, [b,c], (d,e)]", want: []string{"a", "[b,c]", "(d,e)"}},
+		{input: `["x,y", "z\"]"]`, want: []string{`"x,y"`, `"z\"]"`}},
+		{input: "[a,[b]", wantErr: true},
+		{input: `["a]`, wantErr: true},
+		{input: "a,b", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := splitABIList(tt.input, '[', ']')
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("splitABIList(%q): 期望报错，得到 %q", tt.input, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("splitABIList(%q): %v", tt.input, err)
+			continue
+		}
+		if fmt.Sprintf("%q", got) != fmt.Sprintf("%q", tt.want) {
+			t.Errorf("splitABIList(%q) = %q，期望 %q", tt.input, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package commands
+
+import (
+	"fmt"
+	"math/big"
+
+	"transfer-tool/internal/wallet"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/urfave/cli/v2"
+)
+
+// DeployCommand 使用托管钱包部署合约，等待打包后显示合约地址
+func DeployCommand(c *cli.Context) error {
+	bytecodeFile := c.String("bytecode")
+	if bytecodeFile == "" {
+		return fmt.Errorf("用法: transfer-tool deploy --bytecode <file> [--abi <file>] [args...]")
+	}
+	ctx := c.Context
+
+	book, err := loadAddressBook(c)
+	if err != nil {
+		return err
+	}
+	gasOpts, err := wallet.ParseGasOptions(c.String("gas"), c.String("max-fee"), c.String("priority-fee"))
+	if err != nil {
+		return err
+	}
+	bytecode, err := wallet.LoadBytecodeFile(bytecodeFile)
+	if err != nil {
+		return err
+	}
+
+	// 构造函数来自 --abi，未指定时尝试使用编译产物中的ABI
+	var constructor *abi.Method
+	if abiFile := c.String("abi"); abiFile != "" {
+		parsed, err := wallet.LoadABIFile(abiFile)
+		if err != nil {
+			return err
+		}
+		constructor = &parsed.Constructor
+	} else if parsed, err := wallet.LoadABIFile(bytecodeFile); err == nil {
+		constructor = &parsed.Constructor
+	}
+
+	var args []interface{}
+	data := bytecode
+	if constructor != nil {
+		if args, err = parseABIArgs(constructor.Inputs, c.Args().Slice(), book); err != nil {
+			return fmt.Errorf("构造函数参数: %v", err)
+		}
+		packed, err := constructor.Inputs.Pack(args...)
+		if err != nil {
+			return fmt.Errorf("编码构造函数参数失败: %v", err)
+		}
+		data = append(append([]byte{}, bytecode...), packed...)
+	} else if c.NArg() > 0 {
+		return fmt.Errorf("传入构造函数参数时需要通过 --abi 指定ABI文件")
+	}
+
+	wm, err := newWalletManager(c)
+	if err != nil {
+		return err
+	}
+	defer wm.Close()
+
+	if len(wm.GetAddresses()) == 0 {
+		return fmt.Errorf("没有可用的钱包地址")
+	}
+	var senderIndex int
+	if spec := c.String("from"); spec != "" {
+		if senderIndex, err = resolveWallet(wm, book, spec); err != nil {
+			return fmt.Errorf("无效的部署钱包: %v", err)
+		}
+	}
+	fromAddress := wm.GetAddressByIndex(senderIndex)
+
+	value := big.NewInt(0)
+	if amount := c.String("value"); amount != "" {
+		if value, err = wm.ParseNative(amount); err != nil {
+			return fmt.Errorf("无效的金额: %v", err)
+		}
+	}
+	if value.Sign() > 0 && constructor != nil && !constructor.IsPayable() {
+		return fmt.Errorf("构造函数不是 payable，不能附带 --value")
+	}
+
+	// 先模拟部署，构造函数回滚时直接显示原因
+	if _, err := wm.CallContract(ctx, ethereum.CallMsg{From: fromAddress, Value: value, Data: data}, nil); err != nil {
+		return fmt.Errorf("模拟部署失败: %v", err)
+	}
+
+	balance, err := wm.GetBalance(ctx, fromAddress)
+	if err != nil {
+		return fmt.Errorf("查询余额失败: %v", err)
+	}
+	fees, err := wm.SuggestFees(ctx, gasOpts)
+	if err != nil {
+		return err
+	}
+	gasLimit, err := wm.EstimateDeployGas(ctx, fromAddress, value, data)
+	if err != nil {
+		return err
+	}
+	totalCost := new(big.Int).Add(value, fees.MaxCost(gasLimit))
+	if balance.Cmp(totalCost) < 0 {
+		return fmt.Errorf("余额不足: 需要 %s %s，当前余额 %s %s",
+			wm.FormatNative(totalCost), wm.Symbol(), wm.FormatNative(balance), wm.Symbol())
+	}
+
+	fmt.Printf("🚀 部署合约:\n")
+	fmt.Printf("   部署钱包: %s\n", book.Display(fromAddress.Hex()))
+	fmt.Printf("   字节码: %s（%d 字节）\n", bytecodeFile, len(bytecode))
+	if constructor != nil {
+		for i, input := range constructor.Inputs {
+			fmt.Printf("   - %s: %s\n", describeABIArgument(i, input), formatABIValue(input.Type, args[i], book))
+		}
+	}
+	if value.Sign() > 0 {
+		fmt.Printf("   金额: %s %s\n", wm.FormatNative(value), wm.Symbol())
+	}
+	fmt.Printf("   网络: %s\n", wm.GetNetworkConfig().Name)
+	printGasFees(fees)
+	fmt.Printf("   Gas限制: %d\n", gasLimit)
+	fmt.Printf("   最高手续费: %s %s\n", wm.FormatNative(fees.MaxCost(gasLimit)), wm.Symbol())
+
+	// 主网需输入 MAINNET 确认，其他网络询问 y/N
+	if err := confirmTransaction(ctx, wm, c.Bool("yes"), "部署合约", "\n确认部署合约? (y/N): "); err != nil {
+		return err
+	}
+
+	nonces, err := wm.GetPendingNonces(ctx, []common.Address{fromAddress})
+	if err != nil {
+		return err
+	}
+	txHash, err := wm.SendTxWithNonce(ctx, senderIndex, nil, value, data, fees, gasLimit, nonces[0])
+	if err != nil {
+		return fmt.Errorf("部署失败: %v", err)
+	}
+
+	fmt.Printf("\n✅ 部署交易已发送!\n")
+	fmt.Printf("   交易哈希: %s\n", txHash)
+	if explorerURL := wm.GetExplorerURL(txHash); explorerURL != "" {
+		fmt.Printf("   区块浏览器: %s\n", explorerURL)
+	}
+	// 合约地址由部署钱包地址和 nonce 决定
+	fmt.Printf("   预计合约地址: %s\n", crypto.CreateAddress(fromAddress, nonces[0]).Hex())
+	fmt.Printf("⏳ 等待交易打包（按 Ctrl-C 停止等待，不影响已发送的交易）...\n")
+
+	receipt, err := wm.WaitReceipt(ctx, common.HexToHash(txHash))
+	if err != nil {
+		return fmt.Errorf("等待部署交易失败: %v，可使用 tx status %s 查询结果", err, txHash)
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		return fmt.Errorf("部署交易执行失败，可使用 tx show %s 查看回滚原因", txHash)
+	}
+
+	fmt.Printf("\n🎉 合约部署成功!\n")
+	fmt.Printf("   合约地址: %s\n", receipt.ContractAddress.Hex())
+	fmt.Printf("   区块: %s\n", receipt.BlockNumber.String())
+	fmt.Printf("   Gas使用: %d\n", receipt.GasUsed)
+	return nil
+}
@@ -0,0 +1,327 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"transfer-tool/internal/config"
+	"transfer-tool/internal/wallet"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/urfave/cli/v2"
+)
+
+// fundWallet 补足计划中的单个钱包
+type fundWallet struct {
+	index   int
+	balance *big.Int
+	need    *big.Int // 距目标余额的差额，高于目标时为0
+}
+
+// FundCommand 将托管钱包的余额补足到目标值：从指定的出资钱包转出差额，或在钱包之间再平衡
+func FundCommand(c *cli.Context) error {
+	if c.NArg() != 0 {
+		return fmt.Errorf("用法: transfer-tool fund --target <amount> (--from <wallet> | --rebalance)")
+	}
+	if c.String("target") == "" {
+		return fmt.Errorf("请通过 --target 指定目标余额")
+	}
+	rebalance := c.Bool("rebalance")
+	if rebalance == (c.String("from") != "") {
+		return fmt.Errorf("请指定 --from <wallet> 或 --rebalance 其中之一")
+	}
+	ctx := c.Context
+
+	book, err := loadAddressBook(c)
+	if err != nil {
+		return err
+	}
+	gasOpts, err := wallet.ParseGasOptions(c.String("gas"), c.String("max-fee"), c.String("priority-fee"))
+	if err != nil {
+		return err
+	}
+
+	wm, err := newWalletManager(c)
+	if err != nil {
+		return err
+	}
+	defer wm.Close()
+
+	addresses := wm.GetAddresses()
+	if len(addresses) < 2 {
+		return fmt.Errorf("至少需要2个钱包")
+	}
+	target, err := wm.ParseNative(c.String("target"))
+	if err != nil {
+		return fmt.Errorf("无效的目标余额: %v", err)
+	}
+
+	snapshot, err := wm.GetBalanceSnapshot(ctx, addresses, nil, nil)
+	if err != nil {
+		return err
+	}
+	wallets := make([]*fundWallet, len(addresses))
+	for i, result := range snapshot.Results {
+		if result.Err != nil {
+			return fmt.Errorf("查询钱包 %s 余额失败: %v", addresses[i].Hex(), result.Err)
+		}
+		need := new(big.Int).Sub(target, result.Balance)
+		if need.Sign() < 0 {
+			need.SetInt64(0)
+		}
+		wallets[i] = &fundWallet{index: i, balance: result.Balance, need: need}
+	}
+
+	// 出资钱包：指定的钱包，或再平衡时所有高于目标的钱包
+	var senders []int
+	funder := -1
+	if rebalance {
+		for _, w := range wallets {
+			if w.balance.Cmp(target) > 0 {
+				senders = append(senders, w.index)
+			}
+		}
+	} else {
+		if funder, err = resolveWallet(wm, book, c.String("from")); err != nil {
+			return fmt.Errorf("无效的出资钱包: %v", err)
+		}
+		senders = []int{funder}
+	}
+
+	// 每笔补足转账的最高手续费，用于计算出资钱包可用余额
+	fees, err := wm.SuggestFees(ctx, gasOpts)
+	if err != nil {
+		return err
+	}
+	fee, err := estimateFundFees(ctx, wm, wallets, senders, fees)
+	if err != nil {
+		return err
+	}
+
+	var rows []*batchRow
+	if rebalance {
+		rows = planRebalance(wm, wallets, target, fee)
+	} else {
+		rows = planFunding(wm, wallets, funder)
+	}
+
+	// 显示补足计划
+	fmt.Printf("💧 补足计划:\n")
+	fmt.Printf("   目标余额: %s %s\n", wm.FormatNative(target), wm.Symbol())
+	fmt.Printf("   网络: %s\n", wm.GetNetworkConfig().Name)
+	fmt.Printf("   Gas策略: %s\n", gasOpts.Strategy)
+	fmt.Println()
+	printFundPlan(wm, book, wallets, rows)
+	if len(rows) == 0 {
+		fmt.Printf("\n所有钱包余额已达到目标，无需补足\n")
+		return nil
+	}
+
+	// 出资钱包余额不足时提示，执行时不足的转账记为失败
+	indexes := make(map[common.Address]int, len(addresses))
+	for i, address := range addresses {
+		indexes[address] = i
+	}
+	spend := make(map[int]*big.Int)
+	for _, row := range rows {
+		if spend[row.senderIndex] == nil {
+			spend[row.senderIndex] = big.NewInt(0)
+		}
+		spend[row.senderIndex].Add(spend[row.senderIndex], row.amount)
+		spend[row.senderIndex].Add(spend[row.senderIndex], fee(row.senderIndex, indexes[row.to]))
+	}
+	for index, total := range spend {
+		if wallets[index].balance.Cmp(total) < 0 {
+			fmt.Printf("\n⚠️  出资钱包 %s 余额 %s %s 不足以支付全部转账及手续费（约 %s %s），超出部分将失败\n",
+				book.Display(addresses[index].Hex()), wm.FormatNative(wallets[index].balance), wm.Symbol(),
+				wm.FormatNative(total), wm.Symbol())
+		}
+	}
+
+	// 主网需输入 MAINNET 确认，其他网络询问 y/N
+	if err := confirmTransaction(ctx, wm, c.Bool("yes"), "补足钱包余额", fmt.Sprintf("\n确认执行 %d 笔补足转账? (y/N): ", len(rows))); err != nil {
+		return err
+	}
+	fmt.Printf("   按 Ctrl-C 可中断：将不再开始新的转账，已发出的转账会执行完毕并生成报告\n")
+
+	report, err := executeBatchTransfer(ctx, wm, rows, gasOpts, nil, nil, book)
+	if err != nil {
+		return fmt.Errorf("补足失败: %v", err)
+	}
+
+	reportFile := fmt.Sprintf("data/fund_report_%d.md", time.Now().Unix())
+	if err := config.SaveReport(report, reportFile); err != nil {
+		fmt.Printf("⚠️  报告保存失败: %v\n", err)
+	} else {
+		fmt.Printf("📊 报告已保存: %s\n", reportFile)
+	}
+
+	fmt.Printf("\n📊 补足完成:\n")
+	fmt.Printf("   成功: %d\n", report.Summary.Success)
+	fmt.Printf("   失败: %d\n", report.Summary.Failed)
+	if report.Summary.Skipped > 0 {
+		fmt.Printf("   跳过: %d\n", report.Summary.Skipped)
+	}
+	fmt.Printf("   总计: %d\n", report.Summary.Total)
+
+	if report.Interrupted {
+		return fmt.Errorf("补足已中断，剩余 %d 笔未执行，请查看报告详情", report.Summary.Skipped)
+	}
+	if report.Summary.Failed > 0 {
+		return fmt.Errorf("部分转账失败，请查看报告详情")
+	}
+	return nil
+}
+
+// fundFee 计算 from 向 to 补足转账的最高手续费
+type fundFee func(from, to int) *big.Int
+
+// estimateFundFees 计算补足转账的手续费：接收钱包为外部账户时按固定的 21000，
+// 为合约（如 EIP-7702 委托账户）时按每个出资钱包实际估算
+func estimateFundFees(ctx context.Context, wm *wallet.Manager, wallets []*fundWallet, senders []int, fees *wallet.GasFees) (fundFee, error) {
+	addresses := wm.GetAddresses()
+	contractFees := make(map[[2]int]*big.Int)
+	for _, w := range wallets {
+		if w.need.Sign() == 0 {
+			continue
+		}
+		isContract, err := wm.IsContract(ctx, addresses[w.index])
+		if err != nil {
+			return nil, err
+		}
+		if !isContract {
+			continue
+		}
+		for _, from := range senders {
+			if from == w.index {
+				continue
+			}
+			gasLimit, err := wm.EstimateGas(ctx, addresses[from], addresses[w.index], big.NewInt(1), nil)
+			if err != nil {
+				return nil, fmt.Errorf("估算 %s 转入Gas失败: %v", addresses[w.index].Hex(), err)
+			}
+			contractFees[[2]int{from, w.index}] = fees.MaxCost(gasLimit)
+		}
+	}
+
+	transferFee := fees.MaxCost(transferGasLimit)
+	return func(from, to int) *big.Int {
+		if fee, ok := contractFees[[2]int{from, to}]; ok {
+			return fee
+		}
+		return transferFee
+	}, nil
+}
+
+// planFunding 出资钱包向每个低于目标的钱包转出差额
+func planFunding(wm *wallet.Manager, wallets []*fundWallet, funder int) []*batchRow {
+	var rows []*batchRow
+	for _, w := range wallets {
+		if w.index == funder || w.need.Sign() == 0 {
+			continue
+		}
+		rows = append(rows, newFundRow(wm, funder, w.index, w.need))
+	}
+	return rows
+}
+
+// planRebalance 高于目标的钱包将超出部分（扣除手续费）转给低于目标的钱包，差额大的优先
+// 一个钱包的差额可能由多个钱包分担；超出部分不足时部分钱包无法补足
+func planRebalance(wm *wallet.Manager, wallets []*fundWallet, target *big.Int, fee fundFee) []*batchRow {
+	var donors, receivers []*fundWallet
+	available := make(map[int]*big.Int)
+	for _, w := range wallets {
+		if w.need.Sign() > 0 {
+			receivers = append(receivers, w)
+			continue
+		}
+		surplus := new(big.Int).Sub(w.balance, target)
+		if surplus.Sign() > 0 {
+			donors = append(donors, w)
+			available[w.index] = surplus
+		}
+	}
+	sort.SliceStable(receivers, func(i, j int) bool { return receivers[i].need.Cmp(receivers[j].need) > 0 })
+	sort.SliceStable(donors, func(i, j int) bool { return available[donors[i].index].Cmp(available[donors[j].index]) > 0 })
+
+	var rows []*batchRow
+	for _, receiver := range receivers {
+		need := new(big.Int).Set(receiver.need)
+		for _, donor := range donors {
+			if need.Sign() == 0 {
+				break
+			}
+			// 每笔转账都要从超出部分中扣除手续费
+			transferFee := fee(donor.index, receiver.index)
+			spendable := new(big.Int).Sub(available[donor.index], transferFee)
+			if spendable.Sign() <= 0 {
+				continue
+			}
+			amount := need
+			if spendable.Cmp(need) < 0 {
+				amount = spendable
+			}
+			amount = new(big.Int).Set(amount)
+			rows = append(rows, newFundRow(wm, donor.index, receiver.index, amount))
+			available[donor.index].Sub(available[donor.index], new(big.Int).Add(amount, transferFee))
+			need.Sub(need, amount)
+		}
+	}
+	return rows
+}
+
+// newFundRow 构建一笔补足转账，金额为精确值
+func newFundRow(wm *wallet.Manager, from, to int, amount *big.Int) *batchRow {
+	address := wm.GetAddressByIndex(to)
+	recipient := config.Recipient{Address: address.Hex()}
+	recipient.Amount, _ = strconv.ParseFloat(wallet.FormatUnitsExact(amount, wm.GetNetworkConfig().Decimals), 64)
+	return &batchRow{
+		recipient:   recipient,
+		senderIndex: from,
+		to:          address,
+		amount:      amount,
+	}
+}
+
+// printFundPlan 按钱包输出当前余额、差额和补足来源
+func printFundPlan(wm *wallet.Manager, book *config.AddressBook, wallets []*fundWallet, rows []*batchRow) {
+	addresses := wm.GetAddresses()
+	incoming := make(map[common.Address][]string)
+	outgoing := make(map[int]*big.Int)
+	for _, row := range rows {
+		incoming[row.to] = append(incoming[row.to], fmt.Sprintf("%s ← %s",
+			wm.FormatNative(row.amount), book.Display(addresses[row.senderIndex].Hex())))
+		if outgoing[row.senderIndex] == nil {
+			outgoing[row.senderIndex] = big.NewInt(0)
+		}
+		outgoing[row.senderIndex].Add(outgoing[row.senderIndex], row.amount)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "   钱包\t当前余额\t差额\t补足\t\n")
+	for _, fw := range wallets {
+		address := addresses[fw.index]
+		need := "-"
+		if fw.need.Sign() > 0 {
+			need = wm.FormatNative(fw.need)
+		}
+		action := "-"
+		switch {
+		case len(incoming[address]) > 0:
+			action = strings.Join(incoming[address], "，")
+		case outgoing[fw.index] != nil:
+			action = fmt.Sprintf("转出 %s", wm.FormatNative(outgoing[fw.index]))
+		case fw.need.Sign() > 0:
+			action = "无可用资金"
+		}
+		fmt.Fprintf(w, "   %s\t%s\t%s\t%s\t\n", book.Display(address.Hex()), wm.FormatNative(fw.balance), need, action)
+	}
+	w.Flush()
+}
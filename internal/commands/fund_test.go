@@ -0,0 +1,124 @@
+package commands
+
+import (
+	"fmt"
+	"math/big"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"transfer-tool/internal/wallet"
+)
+
+// newTestKeyManager 以 n 个固定私钥创建不连接RPC的钱包管理器
+func newTestKeyManager(t *testing.T, n int) *wallet.Manager {
+	t.Helper()
+	keys := make([]string, n)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("%064x", i+1)
+	}
+	t.Setenv("PRIVATE_KEYS", strings.Join(keys, ","))
+	wm, err := wallet.NewKeyManager(filepath.Join(t.TempDir(), ".env"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return wm
+}
+
+func newFundWallets(target int64, balances ...int64) []*fundWallet {
+	wallets := make([]*fundWallet, len(balances))
+	for i, balance := range balances {
+		need := target - balance
+		if need < 0 {
+			need = 0
+		}
+		wallets[i] = &fundWallet{index: i, balance: big.NewInt(balance), need: big.NewInt(need)}
+	}
+	return wallets
+}
+
+// receiverIndex 补足转账接收钱包的索引
+func receiverIndex(wm *wallet.Manager, row *batchRow) int {
+	for i, address := range wm.GetAddresses() {
+		if address == row.to {
+			return i
+		}
+	}
+	return -1
+}
+
+// formatFundRows 将补足转账格式化为 "发送方->接收方:金额"，便于比较
+func formatFundRows(wm *wallet.Manager, rows []*batchRow) []string {
+	result := make([]string, len(rows))
+	for i, row := range rows {
+		result[i] = fmt.Sprintf("%d->%d:%s", row.senderIndex, receiverIndex(wm, row), row.amount)
+	}
+	return result
+}
+
+func TestPlanFunding(t *testing.T) {
+	wm := newTestKeyManager(t, 4)
+	tests := []struct {
+		name     string
+		balances []int64
+		funder   int
+		want     []string
+	}{
+		{"补足差额", []int64{1000, 70, 100, 95}, 0, []string{"0->1:30", "0->3:5"}},
+		{"出资钱包自身低于目标", []int64{100, 10, 50, 200}, 3, []string{"3->1:90", "3->2:50"}},
+		{"均已达到目标", []int64{100, 100, 150, 100}, 0, []string{}},
+	}
+	for _, tt := range tests {
+		rows := planFunding(wm, newFundWallets(100, tt.balances...), tt.funder)
+		if got := formatFundRows(wm, rows); fmt.Sprint(got) != fmt.Sprint(tt.want) {
+			t.Errorf("%s: 得到 %v，期望 %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestPlanRebalance(t *testing.T) {
+	wm := newTestKeyManager(t, 4)
+	// 普通转账手续费为1，钱包0向合约钱包2转账的手续费为10
+	fee := func(from, to int) *big.Int {
+		if from == 0 && to == 2 {
+			return big.NewInt(10)
+		}
+		return big.NewInt(1)
+	}
+	tests := []struct {
+		name     string
+		balances []int64
+		want     []string
+	}{
+		// 差额大的钱包2优先，由超出部分最多的钱包0补足；钱包0余额用尽后由钱包1补足钱包3
+		{"按差额和超出部分排序", []int64{150, 120, 60, 90}, []string{"0->2:40", "1->3:10"}},
+		// 钱包0超出5，扣除手续费后只能转出4；钱包2超出部分等于手续费，无法转出
+		{"超出部分不足", []int64{105, 0, 101, 100}, []string{"0->1:4"}},
+		// 一个钱包的差额由多个钱包分担，每笔都扣除手续费
+		{"多个钱包分担", []int64{111, 106, 100, 85}, []string{"0->3:10", "1->3:5"}},
+		{"均已达到目标", []int64{100, 100, 100, 100}, []string{}},
+	}
+	for _, tt := range tests {
+		wallets := newFundWallets(100, tt.balances...)
+		rows := planRebalance(wm, wallets, big.NewInt(100), fee)
+		if got := formatFundRows(wm, rows); fmt.Sprint(got) != fmt.Sprint(tt.want) {
+			t.Errorf("%s: 得到 %v，期望 %v", tt.name, got, tt.want)
+		}
+
+		// 转出金额加手续费不得超过超出目标的部分
+		spent := make(map[int]*big.Int)
+		for _, row := range rows {
+			if spent[row.senderIndex] == nil {
+				spent[row.senderIndex] = big.NewInt(0)
+			}
+			rowFee := fee(row.senderIndex, receiverIndex(wm, row))
+			spent[row.senderIndex].Add(spent[row.senderIndex], new(big.Int).Add(row.amount, rowFee))
+		}
+		for index, total := range spent {
+			surplus := new(big.Int).Sub(wallets[index].balance, big.NewInt(100))
+			if total.Cmp(surplus) > 0 {
+				t.Errorf("%s: 钱包%d 转出 %s 超过超出部分 %s", tt.name, index, total, surplus)
+			}
+		}
+	}
+}
@@ -0,0 +1,421 @@
+package commands
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"transfer-tool/internal/config"
+	"transfer-tool/internal/wallet"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/urfave/cli/v2"
+)
+
+// 交易历史扫描参数
+const (
+	defaultHistoryBlocks      = 1000 // 未指定 --from-block 时扫描最近的区块数
+	historyCacheConfirmations = 12   // 只缓存确认数达到该值的区块，避免链重组导致缓存失效
+)
+
+// HistoryCommand 列出托管钱包在区块范围内的原生代币和 ERC-20 转入转出记录
+func HistoryCommand(c *cli.Context) error {
+	ctx := c.Context
+
+	wm, err := newWalletManager(c)
+	if err != nil {
+		return err
+	}
+	defer wm.Close()
+
+	book, err := loadAddressBook(c)
+	if err != nil {
+		return err
+	}
+
+	addresses := wm.GetAddresses()
+	if len(addresses) == 0 {
+		return fmt.Errorf("没有可用的钱包地址")
+	}
+
+	// 区块范围：默认最近 defaultHistoryBlocks 个区块
+	latest, err := wm.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return err
+	}
+	latestBlock := latest.Number.Uint64()
+	toBlock := latestBlock
+	if c.IsSet("to-block") {
+		toBlock = c.Uint64("to-block")
+		if toBlock > latestBlock {
+			return fmt.Errorf("区块 %d 尚未产生（最新区块 %d）", toBlock, latestBlock)
+		}
+	}
+	fromBlock := uint64(0)
+	if toBlock >= defaultHistoryBlocks {
+		fromBlock = toBlock - defaultHistoryBlocks + 1
+	}
+	if c.IsSet("from-block") {
+		fromBlock = c.Uint64("from-block")
+	}
+	if fromBlock > toBlock {
+		return fmt.Errorf("起始区块 %d 大于结束区块 %d", fromBlock, toBlock)
+	}
+
+	fmt.Printf("📜 交易历史: %s (ChainID: %s)，区块 %d - %d\n",
+		wm.GetNetworkConfig().Name, wm.GetChainID().String(), fromBlock, toBlock)
+
+	// 本地缓存：只扫描缓存未覆盖的区块
+	var cache *historyCache
+	cacheFile := historyCacheFile(wm)
+	if !c.Bool("no-cache") {
+		cache, err = loadHistoryCache(cacheFile, wm.GetChainID().String(), addresses)
+		if err != nil {
+			fmt.Printf("⚠️  读取缓存失败，重新扫描: %v\n", err)
+			cache = nil
+		}
+	}
+
+	var records []wallet.TransferRecord
+	segments := [][2]uint64{{fromBlock, toBlock}}
+	if cache != nil {
+		records, segments = cache.plan(fromBlock, toBlock)
+		if len(segments) == 0 {
+			fmt.Printf("   全部区块来自缓存 (%s)\n", cacheFile)
+		}
+	}
+
+	for _, segment := range segments {
+		scanned, err := wm.ScanHistory(ctx, addresses, segment[0], segment[1], func(done, total uint64) {
+			fmt.Printf("\r🔍 扫描区块 %d - %d: %d / %d   ", segment[0], segment[1], done, total)
+		})
+		if err != nil {
+			fmt.Println()
+			return err
+		}
+		fmt.Println()
+		records = append(records, scanned...)
+	}
+	wallet.SortTransferRecords(records)
+
+	// 更新缓存，最新的 historyCacheConfirmations 个区块不缓存
+	if !c.Bool("no-cache") && len(segments) > 0 && latestBlock >= historyCacheConfirmations {
+		safeBlock := latestBlock - historyCacheConfirmations
+		updated := mergeHistoryCache(cache, wm.GetChainID().String(), addresses, fromBlock, toBlock, safeBlock, records)
+		if updated != nil {
+			if err := saveHistoryCache(cacheFile, updated); err != nil {
+				fmt.Printf("⚠️  缓存保存失败: %v\n", err)
+			}
+		}
+	}
+
+	printHistory(wm, book, addresses, records)
+
+	if csvFile := c.String("csv"); csvFile != "" {
+		if err := writeHistoryCSV(csvFile, wm, book, addresses, records); err != nil {
+			return err
+		}
+		fmt.Printf("\n📄 已导出CSV: %s\n", csvFile)
+	}
+	return nil
+}
+
+// historyDirection 记录相对于钱包的方向
+func historyDirection(record *wallet.TransferRecord, address common.Address) string {
+	from := strings.EqualFold(record.From, address.Hex())
+	to := strings.EqualFold(record.To, address.Hex())
+	switch {
+	case from && to:
+		return "自转"
+	case from:
+		return "转出"
+	case to:
+		return "转入"
+	default:
+		return ""
+	}
+}
+
+// historyCounterparty 记录相对于钱包的对方地址
+func historyCounterparty(record *wallet.TransferRecord, address common.Address, book *config.AddressBook) string {
+	other := record.To
+	if !strings.EqualFold(record.From, address.Hex()) {
+		other = record.From
+	}
+	if other == "" {
+		return "（部署合约）"
+	}
+	return book.Display(other)
+}
+
+// historyAsset 资产名称及精度的合并键
+type historyAsset struct {
+	symbol   string
+	token    string
+	decimals int
+}
+
+// printHistory 按钱包输出转账记录，并汇总每种资产的转入、转出、手续费和净变化
+func printHistory(wm *wallet.Manager, book *config.AddressBook, addresses []common.Address, records []wallet.TransferRecord) {
+	for _, address := range addresses {
+		fmt.Printf("\n👛 %s\n", book.Display(address.Hex()))
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		count := 0
+		inbound := make(map[historyAsset]*big.Int)
+		outbound := make(map[historyAsset]*big.Int)
+		var assets []historyAsset
+		fees := big.NewInt(0)
+		track := func(totals map[historyAsset]*big.Int, asset historyAsset, value *big.Int) {
+			if _, ok := inbound[asset]; !ok {
+				inbound[asset], outbound[asset] = big.NewInt(0), big.NewInt(0)
+				assets = append(assets, asset)
+			}
+			totals[asset].Add(totals[asset], value)
+		}
+
+		for i := range records {
+			record := &records[i]
+			direction := historyDirection(record, address)
+			if direction == "" {
+				continue
+			}
+			if count == 0 {
+				fmt.Fprintln(w, "   区块\t时间\t方向\t对方\t金额\t手续费\t交易哈希\t")
+			}
+			count++
+
+			asset := historyAsset{symbol: record.Symbol, token: record.Token, decimals: record.Decimals}
+			switch direction {
+			case "转入":
+				track(inbound, asset, record.Value)
+			case "转出":
+				track(outbound, asset, record.Value)
+			}
+
+			fee := "-"
+			if record.Fee != nil && direction != "转入" {
+				fees.Add(fees, record.Fee)
+				fee = wm.FormatNative(record.Fee)
+			}
+			amount := fmt.Sprintf("%s %s", wallet.FormatUnits(record.Value, record.Decimals), record.Symbol)
+			if record.Failed {
+				amount = "失败"
+			}
+			fmt.Fprintf(w, "   %d\t%s\t%s\t%s\t%s\t%s\t%s\t\n",
+				record.Block,
+				time.Unix(int64(record.Time), 0).Format("2006-01-02 15:04:05"),
+				direction,
+				historyCounterparty(record, address, book),
+				amount,
+				fee,
+				record.TxHash[:10]+"...")
+		}
+		w.Flush()
+
+		if count == 0 {
+			fmt.Printf("   无转账记录\n")
+			continue
+		}
+
+		// 净变化：原生代币计入手续费
+		native := historyAsset{symbol: wm.Symbol(), decimals: wm.GetNetworkConfig().Decimals}
+		if fees.Sign() > 0 {
+			if _, ok := inbound[native]; !ok {
+				inbound[native], outbound[native] = big.NewInt(0), big.NewInt(0)
+				assets = append(assets, native)
+			}
+		}
+		sort.SliceStable(assets, func(i, j int) bool {
+			return assets[i].token == "" && assets[j].token != ""
+		})
+		fmt.Printf("   合计 %d 条:\n", count)
+		for _, asset := range assets {
+			net := new(big.Int).Sub(inbound[asset], outbound[asset])
+			line := fmt.Sprintf("   - %s: 转入 %s，转出 %s", asset.symbol,
+				wallet.FormatUnits(inbound[asset], asset.decimals), wallet.FormatUnits(outbound[asset], asset.decimals))
+			if asset == native {
+				net.Sub(net, fees)
+				line += fmt.Sprintf("，手续费 %s", wallet.FormatUnits(fees, asset.decimals))
+			}
+			fmt.Printf("%s，净变化 %s\n", line, wallet.FormatUnits(net, asset.decimals))
+		}
+	}
+}
+
+// writeHistoryCSV 导出转账记录，每个钱包一行，金额为精确值
+func writeHistoryCSV(filename string, wm *wallet.Manager, book *config.AddressBook, addresses []common.Address, records []wallet.TransferRecord) error {
+	if dir := filepath.Dir(filename); dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("创建目录失败: %v", err)
+		}
+	}
+	file, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf("创建CSV文件失败: %v", err)
+	}
+	defer file.Close()
+
+	w := csv.NewWriter(file)
+	w.Write([]string{"wallet", "wallet_label", "block", "time", "tx_hash", "direction", "counterparty", "counterparty_label",
+		"asset", "token", "amount", "fee", "status"})
+	for _, address := range addresses {
+		for i := range records {
+			record := &records[i]
+			direction := historyDirection(record, address)
+			if direction == "" {
+				continue
+			}
+			counterparty := record.To
+			if !strings.EqualFold(record.From, address.Hex()) {
+				counterparty = record.From
+			}
+			fee := ""
+			if record.Fee != nil && direction != "转入" {
+				fee = wallet.FormatUnitsExact(record.Fee, wm.GetNetworkConfig().Decimals)
+			}
+			status := "success"
+			if record.Failed {
+				status = "failed"
+			}
+			w.Write([]string{
+				address.Hex(),
+				book.Label(address.Hex()),
+				fmt.Sprintf("%d", record.Block),
+				time.Unix(int64(record.Time), 0).Format(time.RFC3339),
+				record.TxHash,
+				direction,
+				counterparty,
+				book.Label(counterparty),
+				record.Symbol,
+				record.Token,
+				wallet.FormatUnitsExact(record.Value, record.Decimals),
+				fee,
+				status,
+			})
+		}
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return fmt.Errorf("写入CSV失败: %v", err)
+	}
+	return nil
+}
+
+// historyCache 本地缓存的已扫描区块范围及其转账记录
+type historyCache struct {
+	ChainID   string                  `json:"chain_id"`
+	Addresses []string                `json:"addresses"`
+	FromBlock uint64                  `json:"from_block"`
+	ToBlock   uint64                  `json:"to_block"`
+	Records   []wallet.TransferRecord `json:"records"`
+}
+
+// historyCacheFile 缓存文件路径，按链ID区分
+func historyCacheFile(wm *wallet.Manager) string {
+	return filepath.Join("data", fmt.Sprintf("history_%s.json", wm.GetChainID().String()))
+}
+
+// historyCacheKey 钱包地址集合，钱包变化后缓存失效
+func historyCacheKey(addresses []common.Address) []string {
+	keys := make([]string, len(addresses))
+	for i, address := range addresses {
+		keys[i] = address.Hex()
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// loadHistoryCache 读取缓存，文件不存在或链ID、钱包不匹配时返回空
+func loadHistoryCache(filename, chainID string, addresses []common.Address) (*historyCache, error) {
+	data, err := os.ReadFile(filename)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var cache historyCache
+	if err := json.Unmarshal(data, &cache); err != nil {
+		return nil, fmt.Errorf("解析缓存文件 %s 失败: %v", filename, err)
+	}
+	if cache.ChainID != chainID || strings.Join(cache.Addresses, ",") != strings.Join(historyCacheKey(addresses), ",") {
+		return nil, nil
+	}
+	return &cache, nil
+}
+
+// plan 返回缓存中位于请求范围内的记录，以及仍需扫描的区块段
+func (h *historyCache) plan(from, to uint64) ([]wallet.TransferRecord, [][2]uint64) {
+	if to < h.FromBlock || from > h.ToBlock {
+		return nil, [][2]uint64{{from, to}}
+	}
+
+	var records []wallet.TransferRecord
+	for _, record := range h.Records {
+		if record.Block >= from && record.Block <= to {
+			records = append(records, record)
+		}
+	}
+	var segments [][2]uint64
+	if from < h.FromBlock {
+		segments = append(segments, [2]uint64{from, h.FromBlock - 1})
+	}
+	if to > h.ToBlock {
+		segments = append(segments, [2]uint64{h.ToBlock + 1, to})
+	}
+	return records, segments
+}
+
+// mergeHistoryCache 将本次结果合并到缓存，只保留不晚于 safeBlock 的连续区块范围
+// 本次范围与原缓存不相连时以本次结果替换缓存；没有可缓存的区块时返回空
+func mergeHistoryCache(cache *historyCache, chainID string, addresses []common.Address, from, to, safeBlock uint64, records []wallet.TransferRecord) *historyCache {
+	if to > safeBlock {
+		to = safeBlock
+	}
+	if from > to {
+		return nil
+	}
+
+	merged := &historyCache{ChainID: chainID, Addresses: historyCacheKey(addresses), FromBlock: from, ToBlock: to}
+	if cache != nil && from <= cache.ToBlock+1 && to+1 >= cache.FromBlock {
+		if cache.FromBlock < merged.FromBlock {
+			merged.FromBlock = cache.FromBlock
+		}
+		if cache.ToBlock > merged.ToBlock {
+			merged.ToBlock = cache.ToBlock
+		}
+		// 原缓存中本次范围以外的记录
+		for _, record := range cache.Records {
+			if record.Block < from || record.Block > to {
+				merged.Records = append(merged.Records, record)
+			}
+		}
+	}
+	for _, record := range records {
+		if record.Block >= from && record.Block <= to {
+			merged.Records = append(merged.Records, record)
+		}
+	}
+	wallet.SortTransferRecords(merged.Records)
+	return merged
+}
+
+// saveHistoryCache 保存缓存
+func saveHistoryCache(filename string, cache *historyCache) error {
+	if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		return fmt.Errorf("创建data目录失败: %v", err)
+	}
+	data, err := json.MarshalIndent(cache, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filename, data, 0644)
+}
@@ -0,0 +1,144 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"transfer-tool/internal/config"
+	"transfer-tool/internal/wallet"
+
+	"github.com/urfave/cli/v2"
+)
+
+// rpcCheckTimeout 单个节点诊断的超时时间
+const rpcCheckTimeout = 15 * time.Second
+
+// RPCCheckCommand RPC节点诊断命令
+func RPCCheckCommand(c *cli.Context) error {
+	appConfig := config.LoadAppConfig()
+	envFile := appConfig.EnvFile
+
+	networks, err := wallet.LoadNetworks()
+	if err != nil {
+		return err
+	}
+
+	// 未指定网络时检查所有已配置网络
+	var names []string
+	for _, name := range c.Args().Slice() {
+		names = append(names, wallet.NormalizeNetworkName(name))
+	}
+	if len(names) == 0 {
+		names, err = wallet.NetworkNames()
+		if err != nil {
+			return err
+		}
+	}
+
+	// --rpc 只能对应一个网络
+	var customRPCs map[string]string
+	if rpcURL := c.String("rpc"); rpcURL != "" {
+		if len(names) != 1 {
+			return fmt.Errorf("使用 --rpc 时需指定一个网络: transfer-tool --rpc <url> rpc check <network>")
+		}
+		customRPCs = map[string]string{names[0]: rpcURL}
+	}
+
+	hasProblem := false
+	for _, name := range names {
+		networkConfig, exists := networks[name]
+		if !exists {
+			return fmt.Errorf("不支持的网络: %s", name)
+		}
+
+		fmt.Printf("\n🌐 %s (%s, 期望链ID %s)\n", networkConfig.Name, name, networkConfig.ChainID.String())
+
+		sources, err := wallet.ListRPCSources(envFile, name, customRPCs)
+		if err != nil {
+			fmt.Printf("   ❌ 读取RPC配置失败: %v\n", err)
+			hasProblem = true
+			continue
+		}
+		if len(sources) == 0 {
+			fmt.Printf("   - 未配置RPC节点\n")
+			continue
+		}
+
+		// 并发诊断所有节点
+		results := make([]wallet.RPCDiagnosis, len(sources))
+		var wg sync.WaitGroup
+		for i, source := range sources {
+			wg.Add(1)
+			go func(i int, source wallet.RPCSource) {
+				defer wg.Done()
+				ctx, cancel := context.WithTimeout(c.Context, rpcCheckTimeout)
+				defer cancel()
+				results[i] = wallet.DiagnoseRPC(ctx, source, networkConfig.ChainID)
+			}(i, source)
+		}
+		wg.Wait()
+
+		for _, result := range results {
+			if !printDiagnosis(result) {
+				hasProblem = true
+			}
+		}
+	}
+
+	if hasProblem {
+		return fmt.Errorf("部分RPC节点存在问题，请检查上方诊断结果")
+	}
+	return nil
+}
+
+// printDiagnosis 输出诊断结果，返回节点是否可用且链ID正确
+func printDiagnosis(result wallet.RPCDiagnosis) bool {
+	fmt.Printf("   %s\n", result.URL)
+	fmt.Printf("      来源: %s\n", result.Source)
+
+	if !result.Reachable {
+		fmt.Printf("      ❌ 不可达: %v\n", result.Error)
+		return false
+	}
+	fmt.Printf("      ✅ 可达，延迟 %dms\n", result.Latency.Milliseconds())
+
+	ok := true
+	if result.ChainIDMatches() {
+		fmt.Printf("      链ID: %s ✅\n", result.ChainID.String())
+	} else {
+		fmt.Printf("      链ID: %s ❌ 期望 %s\n", result.ChainID.String(), result.ExpectedChain.String())
+		ok = false
+	}
+
+	if result.Error != nil {
+		fmt.Printf("      ⚠️  %v\n", result.Error)
+	} else {
+		fmt.Printf("      最新区块: %d (%s前)\n", result.LatestBlock, result.BlockAge.Round(time.Second))
+	}
+
+	if result.ClientVersion != "" {
+		fmt.Printf("      客户端: %s\n", result.ClientVersion)
+	} else {
+		fmt.Printf("      客户端: 未知（不支持 web3_clientVersion）\n")
+	}
+
+	fmt.Printf("      EIP-1559: %s\n", yesNo(result.EIP1559))
+	methods := make([]string, 0, len(wallet.DiagnosedMethods))
+	for _, method := range wallet.DiagnosedMethods {
+		methods = append(methods, fmt.Sprintf("%s %s", method, yesNo(result.Methods[method])))
+	}
+	fmt.Printf("      方法支持: %s\n", strings.Join(methods, ", "))
+
+	return ok
+}
+
+// yesNo 格式化布尔值
+func yesNo(value bool) string {
+	if value {
+		return "✅"
+	}
+	return "❌"
+}
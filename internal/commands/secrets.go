@@ -0,0 +1,115 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"transfer-tool/internal/secrets"
+
+	"github.com/urfave/cli/v2"
+)
+
+// secretKeys 需要检查来源的密钥
+var secretKeys = []string{
+	"PRIVATE_KEYS",
+	"SEPOLIA_RPC_URL",
+	"GOERLI_RPC_URL",
+	"MAINNET_RPC_URL",
+	"BNB_RPC_URL",
+	"POLYGON_RPC_URL",
+	"INFURA_API_KEY",
+	"ALCHEMY_API_KEY",
+	"ANKR_API_KEY",
+}
+
+// SecretsEncryptCommand 将明文.env格式文件加密为密钥文件
+func SecretsEncryptCommand(c *cli.Context) error {
+	if c.NArg() != 1 {
+		return fmt.Errorf("用法: transfer-tool secrets encrypt <plain_env_file> [--out <file>]")
+	}
+
+	plainFile := c.Args().Get(0)
+	plaintext, err := os.ReadFile(plainFile)
+	if err != nil {
+		return fmt.Errorf("读取明文文件失败: %v", err)
+	}
+	if len(secrets.ParseEnv(string(plaintext))) == 0 {
+		return fmt.Errorf("明文文件中没有 KEY=VALUE 格式的配置")
+	}
+
+	outFile := c.String("out")
+	if outFile == "" {
+		outFile = secrets.EncryptedFilePath()
+	}
+	outFile = secrets.ExpandHome(outFile)
+
+	passphrase, err := promptNewPassphrase()
+	if err != nil {
+		return err
+	}
+
+	content, err := secrets.Encrypt(plaintext, passphrase)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(outFile), 0700); err != nil {
+		return fmt.Errorf("创建目录失败: %v", err)
+	}
+	if err := os.WriteFile(outFile, content, 0600); err != nil {
+		return fmt.Errorf("写入加密密钥文件失败: %v", err)
+	}
+
+	fmt.Printf("🔐 加密密钥文件已生成: %s\n", outFile)
+	fmt.Printf("   请确认后删除明文文件: %s\n", plainFile)
+	if outFile != secrets.EncryptedFilePath() {
+		fmt.Printf("   使用前请设置 SECRETS_FILE=%s\n", outFile)
+	}
+
+	return nil
+}
+
+// SecretsCheckCommand 检查各密钥的来源（不显示密钥内容）
+func SecretsCheckCommand(c *cli.Context) error {
+	fmt.Printf("🔑 密钥来源检查:\n")
+	fmt.Printf("   加密密钥文件: %s\n", secrets.EncryptedFilePath())
+
+	for _, key := range secretKeys {
+		value, source, err := secrets.Default().Source(key)
+		switch {
+		case err != nil:
+			fmt.Printf("   ❌ %s: %v\n", key, err)
+		case value == "":
+			fmt.Printf("   - %s: 未配置\n", key)
+		default:
+			fmt.Printf("   ✅ %s: %s\n", key, source)
+		}
+	}
+
+	return nil
+}
+
+// promptNewPassphrase 获取新口令，优先使用 SECRETS_PASSPHRASE，否则要求输入两次
+func promptNewPassphrase() (string, error) {
+	if passphrase := os.Getenv("SECRETS_PASSPHRASE"); passphrase != "" {
+		return passphrase, nil
+	}
+
+	first, err := secrets.ReadPassphrase("请输入口令: ")
+	if err != nil {
+		return "", err
+	}
+	second, err := secrets.ReadPassphrase("请再次输入口令: ")
+	if err != nil {
+		return "", err
+	}
+
+	if first == "" {
+		return "", fmt.Errorf("口令不能为空")
+	}
+	if first != second {
+		return "", fmt.Errorf("两次输入的口令不一致")
+	}
+	return first, nil
+}
@@ -1,18 +1,13 @@
 package commands
 
 import (
-	"bufio"
 	"context"
 	"fmt"
 	"math/big"
-	"os"
-	"strings"
 
-	"transfer-tool/internal/config"
 	"transfer-tool/internal/wallet"
 
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/urfave/cli/v2"
 )
 
@@ -20,146 +15,161 @@ import (
 func SendCommand(c *cli.Context) error {
 	// 检查参数
 	if c.NArg() != 2 {
-		return fmt.Errorf("用法: transfer-tool send <recipient_address> <amount_in_eth>")
+		return fmt.Errorf("用法: transfer-tool send <recipient_address|label> <amount>")
 	}
 
 	recipientStr := c.Args().Get(0)
 	amountStr := c.Args().Get(1)
-	network := c.String("network")
-	appConfig := config.LoadAppConfig()
-	envFile := appConfig.EnvFile
 	skipConfirm := c.Bool("yes")
+	ctx := c.Context
 
-	// 验证接收地址
-	if err := wallet.ValidateAddress(recipientStr); err != nil {
+	// 加载地址簿，接收方支持地址或标签
+	book, err := loadAddressBook(c)
+	if err != nil {
 		return err
 	}
 
-	// 解析金额
-	amount, err := wallet.ParseAmount(amountStr)
+	recipientAddr, err := book.Resolve(recipientStr)
 	if err != nil {
 		return err
 	}
 
-	// 尝试加载全局RPC配置
-	var customRPCs map[string]string
-	if globalRPC, err := config.LoadGlobalRPCConfig(); err == nil {
-		customRPCs = globalRPC
+	gasOpts, err := wallet.ParseGasOptions(c.String("gas"), c.String("max-fee"), c.String("priority-fee"))
+	if err != nil {
+		return err
 	}
 
 	// 创建钱包管理器
-	wm, err := wallet.NewManagerWithRPC(envFile, network, customRPCs)
+	wm, err := newWalletManager(c)
+	if err != nil {
+		return err
+	}
+	defer wm.Close()
+
+	// 按网络原生代币精度解析金额
+	amount, err := wm.ParseNative(amountStr)
 	if err != nil {
 		return err
 	}
-	defer wm.GetClient().Close()
 
-	// 获取发送方地址（第一个钱包）
-	fromAddress := wm.GetFirstAddress()
-	if fromAddress == (common.Address{}) {
+	if len(wm.GetAddresses()) == 0 {
 		return fmt.Errorf("没有可用的钱包地址")
 	}
 
 	// 获取接收方地址
-	toAddress := common.HexToAddress(recipientStr)
+	toAddress := common.HexToAddress(recipientAddr)
+
+	// 选择发送方钱包
+	var senderIndex int
+	switch {
+	case c.Bool("auto") && c.String("from") != "":
+		return fmt.Errorf("--from 和 --auto 不能同时使用")
+	case c.Bool("auto"):
+		senderIndex, err = selectRichestWallet(ctx, wm, toAddress, amount, gasOpts)
+		if err != nil {
+			return err
+		}
+	case c.String("from") != "":
+		senderIndex, err = resolveWallet(wm, book, c.String("from"))
+		if err != nil {
+			return fmt.Errorf("无效的发送钱包: %v", err)
+		}
+	}
+	fromAddress := wm.GetAddressByIndex(senderIndex)
 
 	// 检查余额
-	balance, err := wm.GetBalance(fromAddress)
+	balance, err := wm.GetBalance(ctx, fromAddress)
 	if err != nil {
 		return fmt.Errorf("查询余额失败: %v", err)
 	}
 
-	// 估算Gas费用
-	gasPrice, err := wm.GetGasPrice()
+	// 按Gas策略计算费用
+	fees, err := wm.SuggestFees(ctx, gasOpts)
 	if err != nil {
-		return fmt.Errorf("获取Gas价格失败: %v", err)
+		return err
 	}
 
-	gasLimit, err := wm.EstimateGas(fromAddress, toAddress, amount, nil)
+	gasLimit, err := wm.EstimateGas(ctx, fromAddress, toAddress, amount, nil)
 	if err != nil {
 		return fmt.Errorf("估算Gas失败: %v", err)
 	}
 
-	totalCost := new(big.Int).Add(amount, new(big.Int).Mul(gasPrice, big.NewInt(int64(gasLimit))))
+	totalCost := new(big.Int).Add(amount, fees.MaxCost(gasLimit))
 
 	if balance.Cmp(totalCost) < 0 {
-		return fmt.Errorf("余额不足: 需要 %s ETH，当前余额 %s ETH",
-			wallet.FormatAmount(totalCost), wallet.FormatAmount(balance))
+		return fmt.Errorf("余额不足: 需要 %s %s，当前余额 %s %s",
+			wm.FormatNative(totalCost), wm.Symbol(), wm.FormatNative(balance), wm.Symbol())
 	}
 
 	// 显示交易信息
 	fmt.Printf("📤 转账信息:\n")
-	fmt.Printf("   发送方: %s\n", fromAddress.Hex())
-	fmt.Printf("   接收方: %s\n", toAddress.Hex())
-	fmt.Printf("   金额: %s ETH\n", wallet.FormatAmount(amount))
-	fmt.Printf("   Gas价格: %s Gwei\n", wallet.FormatAmount(new(big.Int).Div(gasPrice, big.NewInt(1e9))))
+	fmt.Printf("   发送方: %s\n", book.Display(fromAddress.Hex()))
+	fmt.Printf("   接收方: %s\n", book.Display(toAddress.Hex()))
+	fmt.Printf("   金额: %s %s\n", wm.FormatNative(amount), wm.Symbol())
+	printGasFees(fees)
 	fmt.Printf("   Gas限制: %d\n", gasLimit)
+	fmt.Printf("   最高手续费: %s %s\n", wm.FormatNative(fees.MaxCost(gasLimit)), wm.Symbol())
 	fmt.Printf("   网络: %s\n", wm.GetNetworkConfig().Name)
 
-	// 主网额外确认
-	if network == "mainnet" {
-		fmt.Printf("\n⚠️  警告: 您正在主网执行转账操作！\n")
-		if !skipConfirm {
-			fmt.Printf("请输入 'MAINNET' 确认: ")
-			reader := bufio.NewReader(os.Stdin)
-			confirm, _ := reader.ReadString('\n')
-			confirm = strings.TrimSpace(confirm)
-			if confirm != "MAINNET" {
-				return fmt.Errorf("操作已取消")
-			}
-		}
-	} else if !skipConfirm {
-		// 其他网络确认
-		fmt.Printf("\n确认执行转账? (y/N): ")
-		reader := bufio.NewReader(os.Stdin)
-		confirm, _ := reader.ReadString('\n')
-		confirm = strings.TrimSpace(strings.ToLower(confirm))
-		if confirm != "y" && confirm != "yes" {
-			return fmt.Errorf("操作已取消")
-		}
+	// 主网需输入 MAINNET 确认，其他网络询问 y/N
+	if err := confirmTransaction(ctx, wm, skipConfirm, "执行转账操作", "\n确认执行转账? (y/N): "); err != nil {
+		return err
 	}
 
 	// 执行转账
-	txHash, err := executeTransfer(wm, fromAddress, toAddress, amount, gasPrice, gasLimit)
+	txHash, err := wm.SendTransfer(ctx, senderIndex, toAddress, amount, fees, gasLimit)
 	if err != nil {
 		return fmt.Errorf("转账失败: %v", err)
 	}
 
 	fmt.Printf("\n✅ 转账成功!\n")
 	fmt.Printf("   交易哈希: %s\n", txHash)
-	fmt.Printf("   区块浏览器: %s\n", wm.GetExplorerURL(txHash))
+	if explorerURL := wm.GetExplorerURL(txHash); explorerURL != "" {
+		fmt.Printf("   区块浏览器: %s\n", explorerURL)
+	}
 
 	return nil
 }
 
-// executeTransfer 执行转账
-func executeTransfer(wm *wallet.Manager, from, to common.Address, value, gasPrice *big.Int, gasLimit uint64) (string, error) {
-	ctx := context.Background()
-
-	// 获取私钥
-	privateKey := wm.GetPrivateKeyByIndex(0)
-
-	// 获取nonce
-	nonce, err := wm.GetClient().PendingNonceAt(ctx, from)
+// selectRichestWallet 选择余额最高且足够支付金额和Gas的钱包
+func selectRichestWallet(ctx context.Context, wm *wallet.Manager, to common.Address, amount *big.Int, gasOpts wallet.GasOptions) (int, error) {
+	addresses := wm.GetAddresses()
+	if len(addresses) == 0 {
+		return 0, fmt.Errorf("没有可用的钱包地址")
+	}
+	snapshot, err := wm.GetBalanceSnapshot(ctx, addresses, nil, nil)
 	if err != nil {
-		return "", fmt.Errorf("获取nonce失败: %v", err)
+		return 0, err
 	}
 
-	// 构建交易
-	tx := types.NewTransaction(nonce, to, value, gasLimit, gasPrice, nil)
+	bestIndex := -1
+	var bestBalance *big.Int
+	for i, result := range snapshot.Results {
+		if result.Err != nil {
+			return 0, fmt.Errorf("查询钱包 %s 余额失败: %v", addresses[i].Hex(), result.Err)
+		}
+		if bestBalance == nil || result.Balance.Cmp(bestBalance) > 0 {
+			bestIndex = i
+			bestBalance = result.Balance
+		}
+	}
 
-	// 签名交易
-	chainID := wm.GetChainID()
-	signedTx, err := types.SignTx(tx, types.NewEIP155Signer(chainID), privateKey)
+	// 余额最高的钱包都不足时，其他钱包也不可能满足
+	from := wm.GetAddressByIndex(bestIndex)
+	fees, err := wm.SuggestFees(ctx, gasOpts)
 	if err != nil {
-		return "", fmt.Errorf("签名交易失败: %v", err)
+		return 0, err
 	}
-
-	// 发送交易
-	err = wm.GetClient().SendTransaction(ctx, signedTx)
+	gasLimit, err := wm.EstimateGas(ctx, from, to, amount, nil)
 	if err != nil {
-		return "", fmt.Errorf("发送交易失败: %v", err)
+		return 0, fmt.Errorf("估算Gas失败: %v", err)
+	}
+
+	totalCost := new(big.Int).Add(amount, fees.MaxCost(gasLimit))
+	if bestBalance.Cmp(totalCost) < 0 {
+		return 0, fmt.Errorf("没有余额足够的钱包: 需要 %s %s，最高余额 %s %s (%s)",
+			wm.FormatNative(totalCost), wm.Symbol(), wm.FormatNative(bestBalance), wm.Symbol(), from.Hex())
 	}
 
-	return signedTx.Hash().Hex(), nil
+	return bestIndex, nil
 }
@@ -0,0 +1,222 @@
+package commands
+
+import (
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+
+	"transfer-tool/internal/wallet"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+	"github.com/urfave/cli/v2"
+)
+
+// SignMessageCommand EIP-191 (personal_sign) 消息签名命令
+func SignMessageCommand(c *cli.Context) error {
+	if c.NArg() != 1 {
+		return fmt.Errorf("用法: transfer-tool sign-message <message>")
+	}
+
+	message, err := parseMessage(c.Args().Get(0), c.Bool("hex"))
+	if err != nil {
+		return err
+	}
+
+	book, err := loadAddressBook(c)
+	if err != nil {
+		return err
+	}
+
+	wm, err := newKeyManager()
+	if err != nil {
+		return err
+	}
+	defer wm.Close()
+
+	index, err := resolveWallet(wm, book, c.String("from"))
+	if err != nil {
+		return err
+	}
+
+	signature, err := wm.SignMessage(index, message)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("✍️  消息签名 (EIP-191):\n")
+	fmt.Printf("   签名者: %s\n", book.Display(wm.GetAddressByIndex(index).Hex()))
+	fmt.Printf("   消息: %s\n", c.Args().Get(0))
+	fmt.Printf("   签名: %s\n", hexutil.Encode(signature))
+
+	return nil
+}
+
+// SignTypedDataCommand EIP-712 结构化数据签名命令
+func SignTypedDataCommand(c *cli.Context) error {
+	if c.NArg() != 1 {
+		return fmt.Errorf("用法: transfer-tool sign-typed-data <json|json_file>")
+	}
+
+	data, err := readJSONArg(c.Args().Get(0))
+	if err != nil {
+		return err
+	}
+
+	typedData, err := wallet.ParseTypedData(data)
+	if err != nil {
+		return err
+	}
+	chainSource, err := fillTypedDataChainID(c, &typedData)
+	if err != nil {
+		return err
+	}
+
+	book, err := loadAddressBook(c)
+	if err != nil {
+		return err
+	}
+
+	wm, err := newKeyManager()
+	if err != nil {
+		return err
+	}
+	defer wm.Close()
+
+	index, err := resolveWallet(wm, book, c.String("from"))
+	if err != nil {
+		return err
+	}
+
+	signature, err := wm.SignTypedData(index, typedData)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("✍️  结构化数据签名 (EIP-712):\n")
+	fmt.Printf("   签名者: %s\n", book.Display(wm.GetAddressByIndex(index).Hex()))
+	fmt.Printf("   主类型: %s\n", typedData.PrimaryType)
+	if typedData.Domain.Name != "" {
+		fmt.Printf("   域名称: %s\n", typedData.Domain.Name)
+	}
+	if typedData.Domain.ChainId != nil {
+		fmt.Printf("   域链ID: %s%s\n", (*big.Int)(typedData.Domain.ChainId).String(), chainSource)
+	}
+	fmt.Printf("   签名: %s\n", hexutil.Encode(signature))
+
+	return nil
+}
+
+// VerifyCommand 签名验证命令，恢复签名者地址
+func VerifyCommand(c *cli.Context) error {
+	if c.NArg() != 2 {
+		return fmt.Errorf("用法: transfer-tool verify <message|json|json_file> <signature>")
+	}
+
+	signature, err := wallet.ParseSignature(c.Args().Get(1))
+	if err != nil {
+		return err
+	}
+
+	var signer common.Address
+	if c.Bool("typed") {
+		data, err := readJSONArg(c.Args().Get(0))
+		if err != nil {
+			return err
+		}
+		typedData, err := wallet.ParseTypedData(data)
+		if err != nil {
+			return err
+		}
+		if _, err := fillTypedDataChainID(c, &typedData); err != nil {
+			return err
+		}
+		signer, err = wallet.RecoverTypedDataSigner(typedData, signature)
+		if err != nil {
+			return err
+		}
+	} else {
+		message, err := parseMessage(c.Args().Get(0), c.Bool("hex"))
+		if err != nil {
+			return err
+		}
+		signer, err = wallet.RecoverMessageSigner(message, signature)
+		if err != nil {
+			return err
+		}
+	}
+
+	fmt.Printf("🔍 签名验证:\n")
+	fmt.Printf("   签名者: %s\n", signer.Hex())
+
+	// 指定了期望地址时进行比对
+	expected := c.String("address")
+	if expected == "" {
+		return nil
+	}
+	if err := wallet.ValidateAddress(expected); err != nil {
+		return err
+	}
+	if common.HexToAddress(expected) != signer {
+		return fmt.Errorf("签名者不匹配: 期望 %s，实际 %s", common.HexToAddress(expected).Hex(), signer.Hex())
+	}
+	fmt.Printf("   ✅ 与期望地址一致\n")
+
+	return nil
+}
+
+// fillTypedDataChainID 结构化数据的域声明了 chainId 字段但未提供值时，使用 --network 指定网络的链ID
+// 返回链ID来源说明，数据中已提供或域未声明 chainId 时为空
+func fillTypedDataChainID(c *cli.Context, typedData *apitypes.TypedData) (string, error) {
+	if typedData.Domain.ChainId != nil || !declaresField(typedData.Types["EIP712Domain"], "chainId") {
+		return "", nil
+	}
+	network := wallet.NormalizeNetworkName(c.String("network"))
+	if network == wallet.AutoNetwork {
+		return "", fmt.Errorf("结构化数据未提供 domain.chainId，请在数据中指定或通过 --network 选择网络")
+	}
+	networkConfig, err := wallet.GetNetwork(network)
+	if err != nil {
+		return "", err
+	}
+	typedData.Domain.ChainId = (*math.HexOrDecimal256)(new(big.Int).Set(networkConfig.ChainID))
+	return fmt.Sprintf("（数据未指定，使用网络 %s）", networkConfig.Name), nil
+}
+
+// declaresField 类型定义中是否包含指定字段
+func declaresField(fields []apitypes.Type, name string) bool {
+	for _, field := range fields {
+		if field.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// parseMessage 解析待签名消息，支持十六进制输入
+func parseMessage(message string, isHex bool) ([]byte, error) {
+	if !isHex {
+		return []byte(message), nil
+	}
+	data, err := hexutil.Decode(message)
+	if err != nil {
+		return nil, fmt.Errorf("无效的十六进制消息: %v", err)
+	}
+	return data, nil
+}
+
+// readJSONArg 读取JSON参数，参数可以是文件路径或JSON字符串
+func readJSONArg(arg string) ([]byte, error) {
+	if strings.HasPrefix(strings.TrimSpace(arg), "{") {
+		return []byte(arg), nil
+	}
+
+	data, err := os.ReadFile(arg)
+	if err != nil {
+		return nil, fmt.Errorf("读取JSON文件失败: %v", err)
+	}
+	return data, nil
+}
@@ -0,0 +1,386 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"transfer-tool/internal/config"
+	"transfer-tool/internal/wallet"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/urfave/cli/v2"
+)
+
+// transferGasLimit 向外部账户转账原生代币的固定Gas，归集时不加缓冲，避免预留的手续费留在钱包
+const transferGasLimit = 21000
+
+// sweepItem 单个钱包的一笔归集转账
+type sweepItem struct {
+	index    int
+	amount   *big.Int
+	data     []byte // ERC-20 transfer 调用数据，原生代币为空
+	gasLimit uint64
+	fee      *big.Int // 最高手续费
+	skip     string   // 不执行的原因
+}
+
+// sweepPhase 一种资产的归集计划
+type sweepPhase struct {
+	token  *wallet.TokenInfo // 为空表示原生代币
+	symbol string
+	format func(*big.Int) string
+	items  []*sweepItem
+}
+
+// SweepCommand 将所有钱包的余额（可选先归集ERC-20代币）扣除手续费后全部转到目标地址
+func SweepCommand(c *cli.Context) error {
+	if c.NArg() != 1 {
+		return fmt.Errorf("用法: transfer-tool sweep [--token <token>] <destination|label>")
+	}
+	ctx := c.Context
+
+	book, err := loadAddressBook(c)
+	if err != nil {
+		return err
+	}
+	destAddr, err := book.Resolve(c.Args().First())
+	if err != nil {
+		return err
+	}
+	dest := common.HexToAddress(destAddr)
+
+	gasOpts, err := wallet.ParseGasOptions(c.String("gas"), c.String("max-fee"), c.String("priority-fee"))
+	if err != nil {
+		return err
+	}
+	if c.String("token") == "" && c.Bool("no-native") {
+		return fmt.Errorf("--no-native 需要与 --token 同时使用")
+	}
+
+	wm, err := newWalletManager(c)
+	if err != nil {
+		return err
+	}
+	defer wm.Close()
+
+	addresses := wm.GetAddresses()
+	if len(addresses) == 0 {
+		return fmt.Errorf("没有可用的钱包地址")
+	}
+
+	var token *wallet.TokenInfo
+	if tokenSpec := c.String("token"); tokenSpec != "" {
+		tokenAddr, err := book.Resolve(tokenSpec)
+		if err != nil {
+			return fmt.Errorf("无效的代币: %v", err)
+		}
+		token, err = wm.GetTokenInfo(ctx, common.HexToAddress(tokenAddr))
+		if err != nil {
+			return err
+		}
+	}
+
+	fees, err := wm.SuggestFees(ctx, gasOpts)
+	if err != nil {
+		return err
+	}
+	phases, err := planSweep(ctx, wm, dest, token, !c.Bool("no-native"), fees)
+	if err != nil {
+		return err
+	}
+
+	// 显示归集计划
+	fmt.Printf("🧹 归集计划:\n")
+	fmt.Printf("   目标地址: %s\n", book.Display(dest.Hex()))
+	fmt.Printf("   网络: %s\n", wm.GetNetworkConfig().Name)
+	printGasFees(fees)
+	fmt.Println()
+	total := printSweepPlan(wm, book, phases)
+	if total == 0 {
+		fmt.Printf("\n没有可归集的余额\n")
+		return nil
+	}
+	if fees.Dynamic() {
+		fmt.Printf("\n   EIP-1559 交易按最高费用预留手续费，实际手续费更低，差额会留在钱包中\n")
+	}
+
+	// 主网需输入 MAINNET 确认，其他网络询问 y/N
+	if err := confirmTransaction(ctx, wm, c.Bool("yes"), "归集资金", fmt.Sprintf("\n确认执行 %d 笔归集转账? (y/N): ", total)); err != nil {
+		return err
+	}
+
+	reports, err := executeSweep(ctx, wm, book, dest, phases, fees)
+	if err != nil {
+		return err
+	}
+
+	failed, interrupted := 0, false
+	timestamp := time.Now().Unix()
+	for i, report := range reports {
+		reportFile := fmt.Sprintf("data/sweep_report_%d_%s.md", timestamp, strings.ToLower(phases[i].symbol))
+		if err := config.SaveReport(report, reportFile); err != nil {
+			fmt.Printf("⚠️  报告保存失败: %v\n", err)
+		} else {
+			fmt.Printf("📊 报告已保存: %s\n", reportFile)
+		}
+		fmt.Printf("   %s: 成功 %d，失败 %d，跳过 %d\n", report.Symbol, report.Summary.Success, report.Summary.Failed, report.Summary.Skipped)
+		failed += report.Summary.Failed
+		interrupted = interrupted || report.Interrupted
+	}
+
+	if interrupted {
+		return fmt.Errorf("归集已中断，请查看报告详情")
+	}
+	if failed > 0 {
+		return fmt.Errorf("部分归集转账失败，请查看报告详情")
+	}
+	return nil
+}
+
+// planSweep 查询余额并计算每个钱包可归集的金额：代币全部转出，原生代币为余额减去本次所有交易的最高手续费
+func planSweep(ctx context.Context, wm *wallet.Manager, dest common.Address, token *wallet.TokenInfo, sweepNative bool, fees *wallet.GasFees) ([]*sweepPhase, error) {
+	addresses := wm.GetAddresses()
+	natives, err := wm.GetBalanceSnapshot(ctx, addresses, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var phases []*sweepPhase
+	var tokenPhase *sweepPhase
+	if token != nil {
+		if tokenPhase, err = planTokenSweep(ctx, wm, dest, token, natives, fees); err != nil {
+			return nil, err
+		}
+		phases = append(phases, tokenPhase)
+	}
+	if !sweepNative {
+		return phases, nil
+	}
+
+	// 目标为合约时按估算值（含缓冲），否则使用固定的 21000
+	gasLimit := uint64(transferGasLimit)
+	isContract, err := wm.IsContract(ctx, dest)
+	if err != nil {
+		return nil, err
+	}
+	if isContract {
+		if gasLimit, err = wm.EstimateGas(ctx, addresses[0], dest, big.NewInt(1), nil); err != nil {
+			return nil, fmt.Errorf("估算Gas失败: %v", err)
+		}
+	}
+	return append(phases, planNativeSweep(wm, dest, natives, tokenPhase, gasLimit, fees)), nil
+}
+
+// planNativeSweep 计算每个钱包的原生代币归集金额：余额减去代币归集预留的手续费和本笔转账的最高手续费
+func planNativeSweep(wm *wallet.Manager, dest common.Address, natives *wallet.BalanceSnapshot, tokenPhase *sweepPhase, gasLimit uint64, fees *wallet.GasFees) *sweepPhase {
+	addresses := wm.GetAddresses()
+
+	// 每个钱包已预留的原生代币（代币归集的手续费）
+	reserved := make([]*big.Int, len(addresses))
+	for i := range reserved {
+		reserved[i] = big.NewInt(0)
+	}
+	if tokenPhase != nil {
+		for i, item := range tokenPhase.items {
+			if item.skip == "" {
+				reserved[i].Add(reserved[i], item.fee)
+			}
+		}
+	}
+
+	phase := &sweepPhase{symbol: wm.Symbol(), format: wm.FormatNative}
+	for i, address := range addresses {
+		item := &sweepItem{index: i, gasLimit: gasLimit, fee: fees.MaxCost(gasLimit)}
+		phase.items = append(phase.items, item)
+
+		result := natives.Results[i]
+		switch {
+		case address == dest:
+			item.skip = "目标地址本身"
+		case result.Err != nil:
+			item.skip = fmt.Sprintf("查询余额失败: %v", result.Err)
+		default:
+			item.amount = new(big.Int).Sub(result.Balance, reserved[i])
+			item.amount.Sub(item.amount, item.fee)
+			if item.amount.Sign() <= 0 {
+				item.skip = "余额不足以支付手续费"
+			}
+		}
+	}
+	return phase
+}
+
+// planTokenSweep 计算每个钱包的代币归集：转出全部代币，原生代币需足够支付手续费
+func planTokenSweep(ctx context.Context, wm *wallet.Manager, dest common.Address, token *wallet.TokenInfo, natives *wallet.BalanceSnapshot, fees *wallet.GasFees) (*sweepPhase, error) {
+	addresses := wm.GetAddresses()
+	balances, err := wm.GetBalanceSnapshot(ctx, addresses, token, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	phase := &sweepPhase{token: token, symbol: token.Symbol, format: token.Format}
+	var msgs []ethereum.CallMsg
+	var pending []*sweepItem
+	for i, address := range addresses {
+		item := &sweepItem{index: i}
+		phase.items = append(phase.items, item)
+
+		result := balances.Results[i]
+		switch {
+		case address == dest:
+			item.skip = "目标地址本身"
+			continue
+		case result.Err != nil:
+			item.skip = fmt.Sprintf("查询余额失败: %v", result.Err)
+			continue
+		case result.Balance.Sign() == 0:
+			item.skip = "无余额"
+			continue
+		}
+
+		item.amount = result.Balance
+		item.data, err = wallet.ERC20ABI.Pack("transfer", dest, result.Balance)
+		if err != nil {
+			return nil, fmt.Errorf("编码代币转账失败: %v", err)
+		}
+		msgs = append(msgs, ethereum.CallMsg{From: address, To: &token.Address, Data: item.data})
+		pending = append(pending, item)
+	}
+
+	estimates, err := wm.EstimateGasBatch(ctx, msgs)
+	if err != nil {
+		return nil, err
+	}
+	for i, estimate := range estimates {
+		item := pending[i]
+		if estimate.Err != nil {
+			item.skip = fmt.Sprintf("估算Gas失败: %v", estimate.Err)
+			continue
+		}
+		item.gasLimit = estimate.Gas
+		item.fee = fees.MaxCost(estimate.Gas)
+
+		native := natives.Results[item.index]
+		if native.Err != nil {
+			item.skip = fmt.Sprintf("查询余额失败: %v", native.Err)
+		} else if native.Balance.Cmp(item.fee) < 0 {
+			item.skip = fmt.Sprintf("%s 不足以支付手续费 %s", wm.Symbol(), wm.FormatNative(item.fee))
+		}
+	}
+	return phase, nil
+}
+
+// printSweepPlan 按钱包输出归集计划，返回需要执行的转账笔数
+func printSweepPlan(wm *wallet.Manager, book *config.AddressBook, phases []*sweepPhase) int {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	header := []string{"   钱包"}
+	for _, phase := range phases {
+		header = append(header, phase.symbol)
+	}
+	fmt.Fprintln(w, strings.Join(append(header, "最高手续费", ""), "\t"))
+
+	count := 0
+	totals := make([]*big.Int, len(phases))
+	for i := range totals {
+		totals[i] = big.NewInt(0)
+	}
+	totalFee := big.NewInt(0)
+	for i, address := range wm.GetAddresses() {
+		cells := []string{"   " + book.Display(address.Hex())}
+		fee := big.NewInt(0)
+		for p, phase := range phases {
+			item := phase.items[i]
+			if item.skip != "" {
+				cells = append(cells, "跳过: "+item.skip)
+				continue
+			}
+			cells = append(cells, phase.format(item.amount))
+			totals[p].Add(totals[p], item.amount)
+			fee.Add(fee, item.fee)
+			count++
+		}
+		totalFee.Add(totalFee, fee)
+		cells = append(cells, wm.FormatNative(fee), "")
+		fmt.Fprintln(w, strings.Join(cells, "\t"))
+	}
+
+	cells := []string{"   合计"}
+	for p, phase := range phases {
+		cells = append(cells, phase.format(totals[p]))
+	}
+	fmt.Fprintln(w, strings.Join(append(cells, wm.FormatNative(totalFee), ""), "\t"))
+	w.Flush()
+	return count
+}
+
+// executeSweep 按计划发送归集转账，每种资产生成一份与批量转账相同格式的报告
+// 同一钱包先发送代币转账再发送原生代币转账，nonce 在本地递增；收到中断信号后剩余转账标记为跳过
+func executeSweep(ctx context.Context, wm *wallet.Manager, book *config.AddressBook, dest common.Address, phases []*sweepPhase, fees *wallet.GasFees) ([]*config.BatchReport, error) {
+	addresses := wm.GetAddresses()
+	nonces, err := wm.GetPendingNonces(ctx, addresses)
+	if err != nil {
+		return nil, err
+	}
+
+	var reports []*config.BatchReport
+	for _, phase := range phases {
+		report := &config.BatchReport{
+			Timestamp: time.Now(),
+			Network:   wm.GetNetworkConfig().Name,
+			ChainID:   wm.GetChainID().String(),
+			Symbol:    phase.symbol,
+			Summary:   &config.BatchSummary{Total: len(phase.items)},
+			Details:   make([]*config.TransferDetail, 0, len(phase.items)),
+
+			AddressBook: book,
+		}
+		reports = append(reports, report)
+
+		fmt.Printf("\n📤 归集 %s...\n", phase.symbol)
+		for _, item := range phase.items {
+			recipient := config.Recipient{Address: dest.Hex()}
+			if item.amount != nil {
+				recipient.Amount, _ = strconv.ParseFloat(phase.format(item.amount), 64)
+			}
+			if !report.Interrupted && ctx.Err() != nil {
+				report.Interrupted = true
+				report.StopReason = "收到中断信号"
+				fmt.Printf("\n⚠️  收到中断信号，停止开始新的转账\n")
+			}
+			if report.Interrupted {
+				report.AddSkippedDetail(item.index, recipient, "收到中断信号，未执行")
+				continue
+			}
+			if item.skip != "" {
+				report.AddSkippedDetail(item.index, recipient, item.skip)
+				continue
+			}
+
+			// 已开始的转账不随中断取消
+			rowCtx := context.WithoutCancel(ctx)
+			var txHash string
+			if phase.token != nil {
+				txHash, err = wm.SendTxWithNonce(rowCtx, item.index, &phase.token.Address, big.NewInt(0), item.data, fees, item.gasLimit, nonces[item.index])
+			} else {
+				txHash, err = wm.SendTransferWithNonce(rowCtx, item.index, dest, item.amount, fees, item.gasLimit, nonces[item.index])
+			}
+			from := addresses[item.index]
+			if err != nil {
+				fmt.Printf("   ❌ %s: %v\n", book.Display(from.Hex()), err)
+				report.AddFailedDetail(item.index, recipient, err.Error())
+				continue
+			}
+			nonces[item.index]++
+			fmt.Printf("   ✅ %s: %s %s → %s\n", book.Display(from.Hex()), phase.format(item.amount), phase.symbol, txHash)
+			report.AddSuccessDetail(item.index, recipient, from.Hex(), txHash, wm.GetExplorerURL(txHash))
+		}
+	}
+	return reports, nil
+}
@@ -0,0 +1,61 @@
+package commands
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+
+	"transfer-tool/internal/wallet"
+)
+
+// TestPlanNativeSweep 原生代币归集金额需扣除代币归集预留的手续费和本笔手续费
+func TestPlanNativeSweep(t *testing.T) {
+	wm := newTestKeyManager(t, 5)
+	addresses := wm.GetAddresses()
+	dest := addresses[3]
+	fees := &wallet.GasFees{MaxFeePerGas: big.NewInt(10), MaxPriorityFeePerGas: big.NewInt(1)}
+	fee := int64(10 * transferGasLimit)
+
+	natives := &wallet.BalanceSnapshot{Results: []wallet.BalanceResult{
+		{Address: addresses[0], Balance: big.NewInt(1000000)},
+		{Address: addresses[1], Balance: big.NewInt(fee)},
+		{Address: addresses[2], Balance: big.NewInt(fee + 1000)},
+		{Address: addresses[3], Balance: big.NewInt(1000000)},
+		{Address: addresses[4], Err: errors.New("timeout")},
+	}}
+	// 钱包1的代币归集被跳过，不预留手续费
+	tokenPhase := &sweepPhase{items: []*sweepItem{
+		{index: 0, fee: big.NewInt(500)},
+		{index: 1, fee: big.NewInt(500), skip: "无余额"},
+		{index: 2, fee: big.NewInt(1000)},
+		{index: 3, skip: "目标地址本身"},
+		{index: 4, skip: "查询余额失败: timeout"},
+	}}
+
+	tests := []struct {
+		tokenPhase *sweepPhase
+		want       []string // 归集金额或跳过原因
+	}{
+		{tokenPhase, []string{"789500", "余额不足以支付手续费", "余额不足以支付手续费", "目标地址本身", "查询余额失败"}},
+		{nil, []string{"790000", "余额不足以支付手续费", "1000", "目标地址本身", "查询余额失败"}},
+	}
+	for _, tt := range tests {
+		phase := planNativeSweep(wm, dest, natives, tt.tokenPhase, transferGasLimit, fees)
+		if len(phase.items) != len(tt.want) {
+			t.Fatalf("得到 %d 项，期望 %d 项", len(phase.items), len(tt.want))
+		}
+		for i, item := range phase.items {
+			if item.gasLimit != transferGasLimit || item.fee.Int64() != fee {
+				t.Errorf("钱包%d: gasLimit %d 手续费 %s", i, item.gasLimit, item.fee)
+			}
+			got := item.skip
+			if got == "" {
+				got = item.amount.String()
+			}
+			if !strings.HasPrefix(got, tt.want[i]) {
+				t.Errorf("代币预留 %v 钱包%d: 得到 %s，期望 %s", tt.tokenPhase != nil, i, got, tt.want[i])
+			}
+		}
+	}
+}
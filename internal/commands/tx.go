@@ -0,0 +1,256 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"transfer-tool/internal/wallet"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/urfave/cli/v2"
+)
+
+// TxSpeedUpCommand 以相同nonce和更高费用重新广播待打包的交易
+func TxSpeedUpCommand(c *cli.Context) error {
+	return replaceTransaction(c, false)
+}
+
+// TxCancelCommand 以相同nonce向自己转账0，取消待打包的交易
+func TxCancelCommand(c *cli.Context) error {
+	return replaceTransaction(c, true)
+}
+
+// TxStatusCommand 查询交易状态：是否打包、执行结果、确认数和回滚原因
+func TxStatusCommand(c *cli.Context) error {
+	return inspectTransaction(c, false)
+}
+
+// TxShowCommand 查询交易详情：在状态之外显示类型、金额、费用、Gas使用和代币转账
+func TxShowCommand(c *cli.Context) error {
+	return inspectTransaction(c, true)
+}
+
+// inspectTransaction 查询交易及收据并显示，detailed 为 true 时显示完整详情
+func inspectTransaction(c *cli.Context, detailed bool) error {
+	if c.NArg() != 1 {
+		return fmt.Errorf("用法: transfer-tool tx %s <tx_hash>", c.Command.Name)
+	}
+	hash, err := parseTxHash(c.Args().First())
+	if err != nil {
+		return err
+	}
+	book, err := loadAddressBook(c)
+	if err != nil {
+		return err
+	}
+	ctx := c.Context
+
+	wm, err := newWalletManager(c)
+	if err != nil {
+		return err
+	}
+	defer wm.Close()
+
+	info, err := wm.GetTxInfo(ctx, hash)
+	if err != nil {
+		return err
+	}
+	tx := info.Tx
+
+	fmt.Printf("📄 交易: %s\n", tx.Hash().Hex())
+	switch {
+	case !info.Mined():
+		fmt.Printf("   状态: ⏳ 等待打包\n")
+	case info.Succeeded():
+		fmt.Printf("   状态: ✅ 成功\n")
+	default:
+		fmt.Printf("   状态: ❌ 失败\n")
+	}
+	if info.Mined() {
+		fmt.Printf("   区块: %s（%s，%d 个确认）\n", info.Receipt.BlockNumber,
+			time.Unix(int64(info.BlockTime), 0).Format("2006-01-02 15:04:05"), info.Confirmations)
+	}
+	if info.Mined() && !info.Succeeded() {
+		reason, err := wm.RevertReason(ctx, info)
+		if err != nil {
+			fmt.Printf("   回滚原因: 无法获取（%v）\n", err)
+		} else {
+			fmt.Printf("   回滚原因: %s\n", reason)
+		}
+	}
+
+	if detailed {
+		fmt.Printf("   类型: %s\n", txTypeName(tx))
+		fmt.Printf("   发送方: %s\n", book.Display(info.From.Hex()))
+		switch {
+		case tx.To() != nil:
+			fmt.Printf("   接收方: %s\n", book.Display(tx.To().Hex()))
+		case info.Succeeded():
+			fmt.Printf("   创建合约: %s\n", info.Receipt.ContractAddress.Hex())
+		default:
+			fmt.Printf("   接收方: （部署合约）\n")
+		}
+		fmt.Printf("   金额: %s %s\n", wm.FormatNative(tx.Value()), wm.Symbol())
+		fmt.Printf("   Nonce: %d\n", tx.Nonce())
+		fmt.Printf("   费用设置: %s\n", describeTxFees(tx))
+		if info.Mined() {
+			used := info.Receipt.GasUsed
+			fmt.Printf("   Gas使用: %d / %d (%.1f%%)\n", used, tx.Gas(), float64(used)*100/float64(tx.Gas()))
+			if fee := info.Fee(); fee != nil {
+				fmt.Printf("   实际Gas价格: %s Gwei\n", wallet.FormatGwei(info.Receipt.EffectiveGasPrice))
+				fmt.Printf("   手续费: %s %s\n", wm.FormatNative(fee), wm.Symbol())
+			}
+		} else {
+			fmt.Printf("   Gas限制: %d\n", tx.Gas())
+		}
+		if data := tx.Data(); len(data) > 0 {
+			fmt.Printf("   输入数据: %d 字节%s\n", len(data), describeSelector(data))
+		}
+
+		if info.Mined() {
+			transfers := wm.DecodeTokenTransfers(ctx, info.Receipt.Logs)
+			if len(transfers) > 0 {
+				fmt.Printf("   代币转账:\n")
+				for _, transfer := range transfers {
+					amount, symbol := transfer.Value.String(), transfer.Token.Hex()
+					if transfer.Info != nil {
+						amount, symbol = transfer.Info.Format(transfer.Value), transfer.Info.Symbol
+					}
+					fmt.Printf("   - %s → %s: %s %s\n",
+						book.Display(transfer.From.Hex()), book.Display(transfer.To.Hex()), amount, symbol)
+				}
+			}
+			fmt.Printf("   日志数量: %d\n", len(info.Receipt.Logs))
+		}
+	}
+
+	if explorerURL := wm.GetExplorerURL(tx.Hash().Hex()); explorerURL != "" {
+		fmt.Printf("   区块浏览器: %s\n", explorerURL)
+	}
+	return nil
+}
+
+// txTypeName 交易类型名称
+func txTypeName(tx *types.Transaction) string {
+	switch tx.Type() {
+	case types.LegacyTxType:
+		return "Legacy (type 0)"
+	case types.AccessListTxType:
+		return "EIP-2930 (type 1)"
+	case types.DynamicFeeTxType:
+		return "EIP-1559 (type 2)"
+	case types.BlobTxType:
+		return "EIP-4844 Blob (type 3)"
+	default:
+		return fmt.Sprintf("type %d", tx.Type())
+	}
+}
+
+// describeSelector 描述调用数据的方法选择器，ERC-20 标准方法显示方法签名
+func describeSelector(data []byte) string {
+	if len(data) < 4 {
+		return ""
+	}
+	selector := hexutil.Encode(data[:4])
+	if method, err := wallet.ERC20ABI.MethodById(data[:4]); err == nil {
+		return fmt.Sprintf("，方法 %s (%s)", selector, method.Sig)
+	}
+	return fmt.Sprintf("，方法 %s", selector)
+}
+
+// replaceTransaction 构建替换交易，确认后广播
+func replaceTransaction(c *cli.Context, cancel bool) error {
+	action := "加速"
+	if cancel {
+		action = "取消"
+	}
+	if c.NArg() != 1 {
+		return fmt.Errorf("用法: transfer-tool tx %s <tx_hash>", c.Command.Name)
+	}
+
+	hash, err := parseTxHash(c.Args().First())
+	if err != nil {
+		return err
+	}
+	gasOpts, err := wallet.ParseGasOptions(c.String("gas"), c.String("max-fee"), c.String("priority-fee"))
+	if err != nil {
+		return err
+	}
+	book, err := loadAddressBook(c)
+	if err != nil {
+		return err
+	}
+	ctx := c.Context
+
+	wm, err := newWalletManager(c)
+	if err != nil {
+		return err
+	}
+	defer wm.Close()
+
+	replacement, err := wm.PrepareReplacement(ctx, hash, cancel, gasOpts)
+	if err != nil {
+		return err
+	}
+	original := replacement.Original
+
+	fmt.Printf("🔁 %s交易:\n", action)
+	fmt.Printf("   原交易: %s\n", original.Hash().Hex())
+	fmt.Printf("   发送方: %s\n", book.Display(replacement.From.Hex()))
+	fmt.Printf("   Nonce: %d\n", original.Nonce())
+	if cancel {
+		fmt.Printf("   替换为: 向自己转账 0 %s\n", wm.Symbol())
+	} else if original.To() == nil {
+		fmt.Printf("   接收方: （部署合约）\n")
+	} else {
+		fmt.Printf("   接收方: %s\n", book.Display(original.To().Hex()))
+		fmt.Printf("   金额: %s %s\n", wm.FormatNative(original.Value()), wm.Symbol())
+	}
+	fmt.Printf("   原费用: %s\n", describeTxFees(original))
+	fmt.Printf("   新费用:\n")
+	printGasFees(replacement.Fees)
+	fmt.Printf("   Gas限制: %d\n", replacement.Tx.Gas())
+	fmt.Printf("   最高手续费: %s %s\n", wm.FormatNative(replacement.Fees.MaxCost(replacement.Tx.Gas())), wm.Symbol())
+	fmt.Printf("   网络: %s\n", wm.GetNetworkConfig().Name)
+
+	// 主网需输入 MAINNET 确认，其他网络询问 y/N
+	if err := confirmTransaction(ctx, wm, c.Bool("yes"), action+"交易", fmt.Sprintf("\n确认%s交易? (y/N): ", action)); err != nil {
+		return err
+	}
+
+	if err := wm.BroadcastTransaction(ctx, replacement.Tx); err != nil {
+		return fmt.Errorf("%s失败: %v", action, err)
+	}
+
+	txHash := replacement.Tx.Hash().Hex()
+	fmt.Printf("\n✅ 已广播替换交易!\n")
+	fmt.Printf("   交易哈希: %s\n", txHash)
+	if explorerURL := wm.GetExplorerURL(txHash); explorerURL != "" {
+		fmt.Printf("   区块浏览器: %s\n", explorerURL)
+	}
+	fmt.Printf("   原交易与替换交易只会有一笔被打包\n")
+
+	return nil
+}
+
+// parseTxHash 解析交易哈希
+func parseTxHash(value string) (common.Hash, error) {
+	value = strings.TrimSpace(value)
+	data, err := hexutil.Decode(value)
+	if err != nil || len(data) != common.HashLength {
+		return common.Hash{}, fmt.Errorf("无效的交易哈希: %s", value)
+	}
+	return common.BytesToHash(data), nil
+}
+
+// describeTxFees 描述交易的费用参数
+func describeTxFees(tx *types.Transaction) string {
+	if tx.Type() == types.DynamicFeeTxType {
+		return fmt.Sprintf("Max Fee %s Gwei / Priority Fee %s Gwei",
+			wallet.FormatGwei(tx.GasFeeCap()), wallet.FormatGwei(tx.GasTipCap()))
+	}
+	return fmt.Sprintf("Gas价格 %s Gwei", wallet.FormatGwei(tx.GasPrice()))
+}
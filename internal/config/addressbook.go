@@ -0,0 +1,132 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultAddressBookFile 默认地址簿文件路径
+const DefaultAddressBookFile = "configs/addressbook.yaml"
+
+// AddressEntry 地址簿条目
+type AddressEntry struct {
+	Address string   `yaml:"address"`
+	Label   string   `yaml:"label"`
+	Tags    []string `yaml:"tags,omitempty"`
+}
+
+// AddressBook 地址簿，为自有钱包和常用收款方提供标签
+type AddressBook struct {
+	Entries []AddressEntry `yaml:"addresses"`
+
+	byAddress map[common.Address]*AddressEntry
+	byLabel   map[string]*AddressEntry
+}
+
+// LoadAddressBook 加载地址簿文件，文件不存在时返回空地址簿
+func LoadAddressBook(path string) (*AddressBook, error) {
+	book := &AddressBook{}
+
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		book.index()
+		return book, nil
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取地址簿失败: %v", err)
+	}
+
+	if err := yaml.Unmarshal(content, book); err != nil {
+		return nil, fmt.Errorf("解析地址簿失败: %v", err)
+	}
+
+	// 验证条目
+	labels := make(map[string]bool)
+	for i, entry := range book.Entries {
+		if !common.IsHexAddress(entry.Address) {
+			return nil, fmt.Errorf("地址簿第%d条地址无效: %s", i+1, entry.Address)
+		}
+
+		label := strings.TrimSpace(entry.Label)
+		if label == "" {
+			return nil, fmt.Errorf("地址簿第%d条缺少标签", i+1)
+		}
+		// 标签不能与地址或钱包索引混淆
+		if common.IsHexAddress(label) {
+			return nil, fmt.Errorf("地址簿标签不能是地址格式: %s", label)
+		}
+		if _, err := strconv.Atoi(label); err == nil {
+			return nil, fmt.Errorf("地址簿标签不能是纯数字: %s", label)
+		}
+
+		key := strings.ToLower(label)
+		if labels[key] {
+			return nil, fmt.Errorf("地址簿标签重复: %s", label)
+		}
+		labels[key] = true
+		book.Entries[i].Label = label
+	}
+
+	book.index()
+	return book, nil
+}
+
+// index 建立地址和标签索引
+func (b *AddressBook) index() {
+	b.byAddress = make(map[common.Address]*AddressEntry, len(b.Entries))
+	b.byLabel = make(map[string]*AddressEntry, len(b.Entries))
+	for i := range b.Entries {
+		entry := &b.Entries[i]
+		b.byAddress[common.HexToAddress(entry.Address)] = entry
+		b.byLabel[strings.ToLower(entry.Label)] = entry
+	}
+}
+
+// Label 获取地址对应的标签，未登记时返回空字符串
+func (b *AddressBook) Label(address string) string {
+	if b == nil || !common.IsHexAddress(address) {
+		return ""
+	}
+	if entry, exists := b.byAddress[common.HexToAddress(address)]; exists {
+		return entry.Label
+	}
+	return ""
+}
+
+// Tags 获取地址对应的标记
+func (b *AddressBook) Tags(address string) []string {
+	if b == nil || !common.IsHexAddress(address) {
+		return nil
+	}
+	if entry, exists := b.byAddress[common.HexToAddress(address)]; exists {
+		return entry.Tags
+	}
+	return nil
+}
+
+// Resolve 将标签或地址解析为地址
+func (b *AddressBook) Resolve(labelOrAddress string) (string, error) {
+	value := strings.TrimSpace(labelOrAddress)
+	if common.IsHexAddress(value) {
+		return common.HexToAddress(value).Hex(), nil
+	}
+
+	if b != nil {
+		if entry, exists := b.byLabel[strings.ToLower(value)]; exists {
+			return common.HexToAddress(entry.Address).Hex(), nil
+		}
+	}
+
+	return "", fmt.Errorf("无效的地址或未登记的标签: %s", value)
+}
+
+// Display 格式化地址显示，有标签时附带标签和标记，如 0x... (treasury #recipient)
+func (b *AddressBook) Display(address string) string {
+	return formatReportAddress(address, b.Label(address), b.Tags(address))
+}
@@ -0,0 +1,123 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const (
+	treasuryAddress = "0x8ba1f109551bD432803012645Ac136ddd64DBA72"
+	qaAddress       = "0x742d35Cc6634C0532925A3B8D4C9dB96C4B4d8B6"
+)
+
+func writeAddressBook(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "addressbook.yaml")
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func loadTestAddressBook(t *testing.T) *AddressBook {
+	t.Helper()
+	book, err := LoadAddressBook(writeAddressBook(t, `addresses:
+  - address: "`+strings.ToLower(treasuryAddress)+`"
+    label: " Treasury "
+    tags: ["recipient"]
+  - address: "`+qaAddress+`"
+    label: "qa-main"
+    tags: ["own", "qa"]
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return book
+}
+
+func TestAddressBookResolve(t *testing.T) {
+	book := loadTestAddressBook(t)
+	tests := []struct {
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{input: "treasury", want: treasuryAddress},
+		{input: "TREASURY", want: treasuryAddress},
+		{input: " qa-main ", want: qaAddress},
+		{input: strings.ToLower(qaAddress), want: qaAddress},
+		{input: "0x0000000000000000000000000000000000000001", want: "0x0000000000000000000000000000000000000001"},
+		{input: "unknown", wantErr: true},
+		{input: "0x1234", wantErr: true},
+		{input: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := book.Resolve(tt.input)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("Resolve(%q) = %s，期望报错", tt.input, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Resolve(%q): %v", tt.input, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Resolve(%q) = %s，期望 %s", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestAddressBookDisplay(t *testing.T) {
+	book := loadTestAddressBook(t)
+	other := "0x0000000000000000000000000000000000000001"
+	tests := []struct {
+		book    *AddressBook
+		address string
+		want    string
+	}{
+		{book, treasuryAddress, treasuryAddress + " (Treasury #recipient)"},
+		{book, strings.ToLower(treasuryAddress), strings.ToLower(treasuryAddress) + " (Treasury #recipient)"},
+		{book, qaAddress, qaAddress + " (qa-main #own #qa)"},
+		{book, other, other},
+		{book, "not-an-address", "not-an-address"},
+		{nil, treasuryAddress, treasuryAddress},
+	}
+	for _, tt := range tests {
+		if got := tt.book.Display(tt.address); got != tt.want {
+			t.Errorf("Display(%s) = %s，期望 %s", tt.address, got, tt.want)
+		}
+	}
+}
+
+func TestLoadAddressBookErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{"无效地址", `addresses: [{address: "0x1234", label: "a"}]`, "地址无效"},
+		{"缺少标签", `addresses: [{address: "` + qaAddress + `", label: " "}]`, "缺少标签"},
+		{"标签为地址", `addresses: [{address: "` + qaAddress + `", label: "` + treasuryAddress + `"}]`, "不能是地址格式"},
+		{"标签为数字", `addresses: [{address: "` + qaAddress + `", label: "1"}]`, "不能是纯数字"},
+		{"标签重复", `addresses: [{address: "` + qaAddress + `", label: "a"}, {address: "` + treasuryAddress + `", label: "A"}]`, "标签重复"},
+	}
+	for _, tt := range tests {
+		_, err := LoadAddressBook(writeAddressBook(t, tt.content))
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("%s: 错误为 %v，期望包含 %q", tt.name, err, tt.wantErr)
+		}
+	}
+
+	// 文件不存在时为空地址簿
+	book, err := LoadAddressBook(filepath.Join(t.TempDir(), "missing.yaml"))
+	if err != nil {
+		t.Fatalf("文件不存在: %v", err)
+	}
+	if got := book.Display(qaAddress); got != qaAddress {
+		t.Errorf("空地址簿 Display = %s", got)
+	}
+}
@@ -3,25 +3,29 @@ package config
 import (
 	"os"
 	"strings"
+
+	"transfer-tool/internal/secrets"
 )
 
 // AppConfig 应用配置
 type AppConfig struct {
-	EnvFile string
+	EnvFile         string
+	AddressBookFile string
 }
 
 // LoadAppConfig 加载应用配置
 func LoadAppConfig() *AppConfig {
 	config := &AppConfig{
-		EnvFile: getEnvFilePath(),
+		EnvFile:         getEnvFilePath(),
+		AddressBookFile: DefaultAddressBookFile,
 	}
 	return config
 }
 
 // getEnvFilePath 获取环境变量文件路径
 func getEnvFilePath() string {
-	// 按优先级查找.env文件
-	envFiles := []string{".env", "configs/.env", "configs/env.example"}
+	// 按优先级查找.env文件（configs/env.example 只是模板，不作为配置）
+	envFiles := []string{".env", "configs/.env"}
 
 	for _, envFile := range envFiles {
 		if _, err := os.Stat(envFile); err == nil {
@@ -35,8 +39,8 @@ func getEnvFilePath() string {
 
 // GetEnvVar 获取环境变量值
 func GetEnvVar(key string) string {
-	// 先从系统环境变量获取
-	if value := os.Getenv(key); value != "" {
+	// 先从密钥来源获取（环境变量、KEY_FILE、KEY_CMD、加密密钥文件）
+	if value, err := secrets.Get(key); err == nil && value != "" {
 		return value
 	}
 
@@ -47,7 +51,7 @@ func GetEnvVar(key string) string {
 // getEnvFromFile 从.env文件读取环境变量
 func getEnvFromFile(envVar string) string {
 	// 尝试从常见的.env文件读取
-	envFiles := []string{".env", "configs/.env", "configs/env.example"}
+	envFiles := []string{".env", "configs/.env"}
 
 	for _, envFile := range envFiles {
 		if _, err := os.Stat(envFile); os.IsNotExist(err) {
@@ -75,4 +79,3 @@ func getEnvFromFile(envVar string) string {
 
 	return ""
 }
-
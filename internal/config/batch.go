@@ -15,14 +15,33 @@ import (
 type BatchConfig struct {
 	Transfer struct {
 		TokenAddress string `yaml:"token_address"`
+		Mode         string `yaml:"mode"`       // sequential（逐笔转账，默认）| disperse（通过 Disperse 合约每批一笔交易）
+		ChunkSize    int    `yaml:"chunk_size"` // disperse 模式每笔交易的接收方数量，默认 100
 	} `yaml:"transfer"`
 	DataSources struct {
 		RecipientsXlsx string `yaml:"recipients_xlsx"`
 	} `yaml:"data_sources"`
+	Gas       GasConfig         `yaml:"gas,omitempty"`
 	RPCConfig map[string]string `yaml:"rpc_config,omitempty"`
 	APIKeys   map[string]string `yaml:"api_keys,omitempty"`
 }
 
+// GasConfig 批量转账的Gas策略
+type GasConfig struct {
+	Strategy        string `yaml:"strategy"`          // slow | standard | fast | custom，默认 standard
+	MaxFeeGwei      string `yaml:"max_fee_gwei"`      // custom 策略的 maxFeePerGas（传统网络为 gasPrice）
+	PriorityFeeGwei string `yaml:"priority_fee_gwei"` // custom 策略的 maxPriorityFeePerGas，可选
+
+	// Gas价格门槛：base fee（传统网络为 gasPrice）高于阈值时暂停，回落后自动继续
+	MaxBaseFeeGwei string `yaml:"max_base_fee_gwei"`
+	MaxWait        string `yaml:"max_wait"`      // 累计最长等待时间，默认 1h
+	PollInterval   string `yaml:"poll_interval"` // 等待期间查询间隔，默认 30s
+
+	// 自动加速：发出后超过指定区块数仍未打包的交易以更高费用重新广播
+	ReplaceAfterBlocks int `yaml:"replace_after_blocks"` // 0 或不填表示不等待打包
+	MaxReplacements    int `yaml:"max_replacements"`     // 每笔交易最多加速次数，默认 3
+}
+
 // Recipient 接收方信息
 type Recipient struct {
 	Address string  `json:"address"`
@@ -34,8 +53,23 @@ type BatchReport struct {
 	Timestamp time.Time         `json:"timestamp"`
 	Network   string            `json:"network"`
 	ChainID   string            `json:"chain_id"`
+	Symbol    string            `json:"symbol"`
 	Summary   *BatchSummary     `json:"summary"`
 	Details   []*TransferDetail `json:"details"`
+
+	// Interrupted 批量转账被中断（如 Ctrl-C 或Gas等待超时），剩余记录标记为跳过
+	Interrupted bool   `json:"interrupted,omitempty"`
+	StopReason  string `json:"stop_reason,omitempty"`
+
+	// GasWait Gas价格高于门槛时累计暂停的时间
+	GasWait time.Duration `json:"gas_wait,omitempty"`
+
+	// Disperse disperse 模式使用的合约地址及发出的交易数，逐笔转账时为空
+	Disperse    string `json:"disperse,omitempty"`
+	DisperseTxs int    `json:"disperse_txs,omitempty"`
+
+	// AddressBook 用于在报告中显示地址标签，可为空
+	AddressBook *AddressBook `json:"-"`
 }
 
 // BatchSummary 批量转账汇总
@@ -43,6 +77,7 @@ type BatchSummary struct {
 	Total   int `json:"total"`
 	Success int `json:"success"`
 	Failed  int `json:"failed"`
+	Skipped int `json:"skipped"`
 }
 
 // TransferDetail 转账详情
@@ -54,6 +89,14 @@ type TransferDetail struct {
 	Explorer  string `json:"explorer,omitempty"`
 	Status    string `json:"status"`
 	Error     string `json:"error,omitempty"`
+
+	// Replaced 自动加速时被替换、未打包的交易哈希
+	Replaced []string `json:"replaced_tx_hashes,omitempty"`
+
+	RecipientLabel string   `json:"recipient_label,omitempty"`
+	RecipientTags  []string `json:"recipient_tags,omitempty"`
+	SenderLabel    string   `json:"sender_label,omitempty"`
+	SenderTags     []string `json:"sender_tags,omitempty"`
 }
 
 // LoadBatchConfig 加载批量转账配置
@@ -97,24 +140,9 @@ func LoadBatchConfig(configFile string) (*BatchConfig, error) {
 
 // LoadGlobalRPCConfig 加载全局RPC配置
 func LoadGlobalRPCConfig() (map[string]string, error) {
-	// 尝试加载合并后的配置文件
-	configFile := "configs/config.yaml"
-	if _, err := os.Stat(configFile); os.IsNotExist(err) {
-		return nil, fmt.Errorf("配置文件不存在: %s", configFile)
-	}
-
-	content, err := os.ReadFile(configFile)
+	config, err := LoadGlobalConfig()
 	if err != nil {
-		return nil, fmt.Errorf("读取配置文件失败: %v", err)
-	}
-
-	// 解析YAML配置
-	var config struct {
-		RPCConfig map[string]string `yaml:"rpc_config"`
-	}
-	err = yaml.Unmarshal(content, &config)
-	if err != nil {
-		return nil, fmt.Errorf("解析配置文件失败: %v", err)
+		return nil, err
 	}
 
 	if config.RPCConfig == nil {
@@ -261,7 +289,22 @@ func generateMarkdownReport(report *BatchReport) string {
 	content.WriteString("## 基本信息\n\n")
 	content.WriteString(fmt.Sprintf("- **时间**: %s\n", report.Timestamp.Format("2006-01-02 15:04:05")))
 	content.WriteString(fmt.Sprintf("- **网络**: %s\n", report.Network))
-	content.WriteString(fmt.Sprintf("- **链ID**: %s\n\n", report.ChainID))
+	content.WriteString(fmt.Sprintf("- **链ID**: %s\n", report.ChainID))
+	if report.Interrupted {
+		content.WriteString(fmt.Sprintf("- **状态**: ⚠️ 已中断（%s），未执行的记录标记为跳过\n", report.StopReason))
+	}
+	if report.GasWait > 0 {
+		content.WriteString(fmt.Sprintf("- **Gas等待**: %s\n", report.GasWait.Round(time.Second)))
+	}
+	if report.Disperse != "" {
+		content.WriteString(fmt.Sprintf("- **转账方式**: Disperse 合约 %s，共 %d 笔交易，同一交易的接收方共用交易哈希\n", report.Disperse, report.DisperseTxs))
+	}
+	content.WriteString("\n")
+
+	symbol := report.Symbol
+	if symbol == "" {
+		symbol = "ETH"
+	}
 
 	// 汇总信息
 	content.WriteString("## 转账汇总\n\n")
@@ -270,23 +313,31 @@ func generateMarkdownReport(report *BatchReport) string {
 	content.WriteString(fmt.Sprintf("| 总计 | %d |\n", report.Summary.Total))
 	content.WriteString(fmt.Sprintf("| 成功 | %d |\n", report.Summary.Success))
 	content.WriteString(fmt.Sprintf("| 失败 | %d |\n", report.Summary.Failed))
+	if report.Summary.Skipped > 0 {
+		content.WriteString(fmt.Sprintf("| 跳过 | %d |\n", report.Summary.Skipped))
+	}
 	content.WriteString(fmt.Sprintf("| 成功率 | %.2f%% |\n\n", float64(report.Summary.Success)/float64(report.Summary.Total)*100))
 
 	// 成功转账详情
 	if report.Summary.Success > 0 {
 		content.WriteString("## 成功转账详情\n\n")
-		content.WriteString("| 序号 | 接收地址 | 金额(ETH) | 发送地址 | 交易哈希 | 区块浏览器 |\n")
+		content.WriteString(fmt.Sprintf("| 序号 | 接收地址 | 金额(%s) | 发送地址 | 交易哈希 | 区块浏览器 |\n", symbol))
 		content.WriteString("|------|----------|-----------|----------|----------|------------|\n")
 
 		for _, detail := range report.Details {
 			if detail.Status == "success" {
-				content.WriteString(fmt.Sprintf("| %d | %s | %.6f | %s | [%s](%s) | [查看](%s) |\n",
+				replaced := ""
+				if len(detail.Replaced) > 0 {
+					replaced = fmt.Sprintf("（已加速 %d 次）", len(detail.Replaced))
+				}
+				content.WriteString(fmt.Sprintf("| %d | %s | %.6f | %s | [%s](%s)%s | [查看](%s) |\n",
 					detail.Index+1,
-					detail.Recipient.Address,
+					formatReportAddress(detail.Recipient.Address, detail.RecipientLabel, detail.RecipientTags),
 					detail.Recipient.Amount,
-					detail.Sender,
+					formatReportAddress(detail.Sender, detail.SenderLabel, detail.SenderTags),
 					detail.TxHash[:10]+"...",
 					detail.TxHash,
+					replaced,
 					detail.Explorer))
 			}
 		}
@@ -296,14 +347,32 @@ func generateMarkdownReport(report *BatchReport) string {
 	// 失败转账详情
 	if report.Summary.Failed > 0 {
 		content.WriteString("## 失败转账详情\n\n")
-		content.WriteString("| 序号 | 接收地址 | 金额(ETH) | 错误信息 |\n")
+		content.WriteString(fmt.Sprintf("| 序号 | 接收地址 | 金额(%s) | 错误信息 |\n", symbol))
 		content.WriteString("|------|----------|-----------|----------|\n")
 
 		for _, detail := range report.Details {
 			if detail.Status == "failed" {
 				content.WriteString(fmt.Sprintf("| %d | %s | %.6f | %s |\n",
 					detail.Index+1,
-					detail.Recipient.Address,
+					formatReportAddress(detail.Recipient.Address, detail.RecipientLabel, detail.RecipientTags),
+					detail.Recipient.Amount,
+					detail.Error))
+			}
+		}
+		content.WriteString("\n")
+	}
+
+	// 跳过转账详情
+	if report.Summary.Skipped > 0 {
+		content.WriteString("## 跳过转账详情\n\n")
+		content.WriteString(fmt.Sprintf("| 序号 | 接收地址 | 金额(%s) | 原因 |\n", symbol))
+		content.WriteString("|------|----------|-----------|------|\n")
+
+		for _, detail := range report.Details {
+			if detail.Status == "skipped" {
+				content.WriteString(fmt.Sprintf("| %d | %s | %.6f | %s |\n",
+					detail.Index+1,
+					formatReportAddress(detail.Recipient.Address, detail.RecipientLabel, detail.RecipientTags),
 					detail.Recipient.Amount,
 					detail.Error))
 			}
@@ -323,30 +392,72 @@ func generateMarkdownReport(report *BatchReport) string {
 	return content.String()
 }
 
+// formatReportAddress 格式化报告中的地址，有标签时附带标签和标记，如 0x... (treasury #recipient)
+func formatReportAddress(address, label string, tags []string) string {
+	if label == "" {
+		return address
+	}
+	for _, tag := range tags {
+		label += " #" + tag
+	}
+	return fmt.Sprintf("%s (%s)", address, label)
+}
+
 // AddSuccessDetail 添加成功记录
-func (r *BatchReport) AddSuccessDetail(index int, recipient Recipient, sender, txHash, explorer string) {
-	r.Details = append(r.Details, &TransferDetail{
-		Index:     index,
-		Recipient: recipient,
-		Sender:    sender,
-		TxHash:    txHash,
-		Explorer:  explorer,
-		Status:    "success",
-	})
+func (r *BatchReport) AddSuccessDetail(index int, recipient Recipient, sender, txHash, explorer string) *TransferDetail {
+	detail := &TransferDetail{
+		Index:          index,
+		Recipient:      recipient,
+		Sender:         sender,
+		TxHash:         txHash,
+		Explorer:       explorer,
+		Status:         "success",
+		RecipientLabel: r.AddressBook.Label(recipient.Address),
+		RecipientTags:  r.AddressBook.Tags(recipient.Address),
+		SenderLabel:    r.AddressBook.Label(sender),
+		SenderTags:     r.AddressBook.Tags(sender),
+	}
+	r.Details = append(r.Details, detail)
 	r.Summary.Success++
+	return detail
 }
 
 // AddFailedDetail 添加失败记录
 func (r *BatchReport) AddFailedDetail(index int, recipient Recipient, errorMsg string) {
 	r.Details = append(r.Details, &TransferDetail{
-		Index:     index,
-		Recipient: recipient,
-		Status:    "failed",
-		Error:     errorMsg,
+		Index:          index,
+		Recipient:      recipient,
+		Status:         "failed",
+		Error:          errorMsg,
+		RecipientLabel: r.AddressBook.Label(recipient.Address),
+		RecipientTags:  r.AddressBook.Tags(recipient.Address),
 	})
 	r.Summary.Failed++
 }
 
+// MarkFailed 将已发送的记录改为失败（如交易打包后执行回滚），并调整汇总
+func (r *BatchReport) MarkFailed(detail *TransferDetail, errorMsg string) {
+	if detail.Status == "success" {
+		r.Summary.Success--
+		r.Summary.Failed++
+	}
+	detail.Status = "failed"
+	detail.Error = errorMsg
+}
+
+// AddSkippedDetail 添加跳过记录（未执行）
+func (r *BatchReport) AddSkippedDetail(index int, recipient Recipient, reason string) {
+	r.Details = append(r.Details, &TransferDetail{
+		Index:          index,
+		Recipient:      recipient,
+		Status:         "skipped",
+		Error:          reason,
+		RecipientLabel: r.AddressBook.Label(recipient.Address),
+		RecipientTags:  r.AddressBook.Tags(recipient.Address),
+	})
+	r.Summary.Skipped++
+}
+
 // processEnvVariables 处理环境变量替换
 func processEnvVariables(config BatchConfig) BatchConfig {
 	// 处理RPC配置中的环境变量
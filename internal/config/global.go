@@ -0,0 +1,66 @@
+package config
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/ethereum/go-ethereum/common"
+	"gopkg.in/yaml.v3"
+)
+
+// GlobalConfigFile 全局配置文件路径
+const GlobalConfigFile = "configs/config.yaml"
+
+// GlobalConfig 全局配置（configs/config.yaml）
+type GlobalConfig struct {
+	RPCConfig      map[string]string `yaml:"rpc_config"`
+	WatchAddresses []string          `yaml:"watch_addresses,omitempty"`
+}
+
+// LoadGlobalConfig 加载全局配置文件
+func LoadGlobalConfig() (*GlobalConfig, error) {
+	if _, err := os.Stat(GlobalConfigFile); os.IsNotExist(err) {
+		return nil, fmt.Errorf("配置文件不存在: %s", GlobalConfigFile)
+	}
+
+	content, err := os.ReadFile(GlobalConfigFile)
+	if err != nil {
+		return nil, fmt.Errorf("读取配置文件失败: %v", err)
+	}
+
+	var config GlobalConfig
+	if err := yaml.Unmarshal(content, &config); err != nil {
+		return nil, fmt.Errorf("解析配置文件失败: %v", err)
+	}
+
+	return &config, nil
+}
+
+// LoadWatchAddresses 加载只读监控地址列表，未配置时返回空列表
+func LoadWatchAddresses() ([]common.Address, error) {
+	config, err := LoadGlobalConfig()
+	if err != nil {
+		if _, statErr := os.Stat(GlobalConfigFile); os.IsNotExist(statErr) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	addresses := make([]common.Address, 0, len(config.WatchAddresses))
+	seen := make(map[common.Address]bool)
+	for _, addressStr := range config.WatchAddresses {
+		addressStr = expandEnvVariables(addressStr)
+		if !common.IsHexAddress(addressStr) {
+			return nil, fmt.Errorf("watch_addresses 中的地址无效: %s", addressStr)
+		}
+
+		address := common.HexToAddress(addressStr)
+		if seen[address] {
+			continue
+		}
+		seen[address] = true
+		addresses = append(addresses, address)
+	}
+
+	return addresses, nil
+}
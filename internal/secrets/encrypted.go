@@ -0,0 +1,270 @@
+package secrets
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	"golang.org/x/crypto/scrypt"
+	"golang.org/x/term"
+)
+
+// DefaultEncryptedFile 默认加密密钥文件路径（位于项目目录之外）
+const DefaultEncryptedFile = "~/.transfer-tool/secrets.enc"
+
+// scrypt 参数
+const (
+	scryptN      = 1 << 15
+	scryptR      = 8
+	scryptP      = 1
+	scryptKeyLen = 32
+)
+
+// encryptedFile 加密密钥文件格式
+type encryptedFile struct {
+	Version    int    `json:"version"`
+	KDF        string `json:"kdf"`
+	N          int    `json:"n"`
+	R          int    `json:"r"`
+	P          int    `json:"p"`
+	Salt       string `json:"salt"`
+	Nonce      string `json:"nonce"`
+	Ciphertext string `json:"ciphertext"`
+}
+
+// EncryptedFilePath 获取加密密钥文件路径，可通过 SECRETS_FILE 覆盖
+func EncryptedFilePath() string {
+	if path := strings.TrimSpace(os.Getenv("SECRETS_FILE")); path != "" {
+		return ExpandHome(path)
+	}
+	return ExpandHome(DefaultEncryptedFile)
+}
+
+// EncryptedFileProvider 从 scrypt + AES-GCM 加密的密钥文件读取密钥
+// 文件内容为 KEY=VALUE 格式，首次查找时解锁，整个进程只解锁一次
+type EncryptedFileProvider struct {
+	path string
+
+	once    sync.Once
+	values  map[string]string
+	loadErr error
+}
+
+// NewEncryptedFileProvider 创建加密密钥文件来源
+func NewEncryptedFileProvider(path string) *EncryptedFileProvider {
+	return &EncryptedFileProvider{path: path}
+}
+
+// Name 来源名称
+func (p *EncryptedFileProvider) Name() string { return "加密密钥文件" }
+
+// Lookup 查找密钥
+func (p *EncryptedFileProvider) Lookup(key string) (string, bool, error) {
+	// 文件不存在时视为未配置
+	if _, err := os.Stat(p.path); os.IsNotExist(err) {
+		return "", false, nil
+	}
+
+	p.once.Do(p.unlock)
+	if p.loadErr != nil {
+		return "", false, p.loadErr
+	}
+
+	value, found := p.values[key]
+	return value, found, nil
+}
+
+// unlock 读取口令并解密密钥文件
+func (p *EncryptedFileProvider) unlock() {
+	passphrase, err := readPassphrase(p.path)
+	if err != nil {
+		p.loadErr = err
+		return
+	}
+
+	content, err := os.ReadFile(p.path)
+	if err != nil {
+		p.loadErr = fmt.Errorf("读取加密密钥文件失败: %v", err)
+		return
+	}
+
+	plaintext, err := Decrypt(content, passphrase)
+	if err != nil {
+		p.loadErr = err
+		return
+	}
+
+	p.values = ParseEnv(string(plaintext))
+}
+
+// readPassphrase 获取解锁口令：SECRETS_PASSPHRASE（及其 _FILE/_CMD 形式）或终端输入
+func readPassphrase(path string) (string, error) {
+	passphrase, err := NewResolver(EnvProvider{}, FileProvider{}, CommandProvider{}, DotenvProvider{}).Get("SECRETS_PASSPHRASE")
+	if err != nil {
+		return "", err
+	}
+	if passphrase != "" {
+		return passphrase, nil
+	}
+
+	// 非交互环境无法输入口令
+	if info, err := os.Stdin.Stat(); err != nil || info.Mode()&os.ModeCharDevice == 0 {
+		return "", fmt.Errorf("未提供加密密钥文件口令，请设置 SECRETS_PASSPHRASE")
+	}
+
+	return ReadPassphrase(fmt.Sprintf("🔑 请输入密钥文件口令 (%s): ", path))
+}
+
+// ReadPassphrase 显示提示并读取一行口令：终端输入时不回显，否则从标准输入读取
+func ReadPassphrase(prompt string) (string, error) {
+	fmt.Fprint(os.Stderr, prompt)
+	fd := int(os.Stdin.Fd())
+	if term.IsTerminal(fd) {
+		passphrase, err := term.ReadPassword(fd)
+		fmt.Fprintln(os.Stderr)
+		if err != nil {
+			return "", fmt.Errorf("读取口令失败: %v", err)
+		}
+		return string(passphrase), nil
+	}
+
+	// 逐字节读取，不预读后续输入，多次调用可依次读取多行
+	var line []byte
+	buf := make([]byte, 1)
+	for {
+		n, err := os.Stdin.Read(buf)
+		if n > 0 {
+			if buf[0] == '\n' {
+				break
+			}
+			line = append(line, buf[0])
+		}
+		if err == io.EOF && len(line) > 0 {
+			break
+		}
+		if err != nil {
+			return "", fmt.Errorf("读取口令失败: %v", err)
+		}
+	}
+	return strings.TrimRight(string(line), "\r"), nil
+}
+
+// Encrypt 使用口令加密密钥内容
+func Encrypt(plaintext []byte, passphrase string) ([]byte, error) {
+	if passphrase == "" {
+		return nil, fmt.Errorf("口令不能为空")
+	}
+
+	salt := make([]byte, 32)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("生成随机盐失败: %v", err)
+	}
+
+	key, err := scrypt.Key([]byte(passphrase), salt, scryptN, scryptR, scryptP, scryptKeyLen)
+	if err != nil {
+		return nil, fmt.Errorf("派生密钥失败: %v", err)
+	}
+
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("生成随机数失败: %v", err)
+	}
+
+	file := encryptedFile{
+		Version:    1,
+		KDF:        "scrypt",
+		N:          scryptN,
+		R:          scryptR,
+		P:          scryptP,
+		Salt:       base64.StdEncoding.EncodeToString(salt),
+		Nonce:      base64.StdEncoding.EncodeToString(nonce),
+		Ciphertext: base64.StdEncoding.EncodeToString(gcm.Seal(nil, nonce, plaintext, nil)),
+	}
+	return json.MarshalIndent(file, "", "  ")
+}
+
+// Decrypt 使用口令解密密钥内容
+func Decrypt(content []byte, passphrase string) ([]byte, error) {
+	var file encryptedFile
+	if err := json.Unmarshal(content, &file); err != nil {
+		return nil, fmt.Errorf("解析加密密钥文件失败: %v", err)
+	}
+	if file.Version != 1 || file.KDF != "scrypt" {
+		return nil, fmt.Errorf("不支持的加密密钥文件格式: version=%d kdf=%s", file.Version, file.KDF)
+	}
+
+	salt, err := base64.StdEncoding.DecodeString(file.Salt)
+	if err != nil {
+		return nil, fmt.Errorf("加密密钥文件salt无效: %v", err)
+	}
+	nonce, err := base64.StdEncoding.DecodeString(file.Nonce)
+	if err != nil {
+		return nil, fmt.Errorf("加密密钥文件nonce无效: %v", err)
+	}
+	ciphertext, err := base64.StdEncoding.DecodeString(file.Ciphertext)
+	if err != nil {
+		return nil, fmt.Errorf("加密密钥文件密文无效: %v", err)
+	}
+
+	key, err := scrypt.Key([]byte(passphrase), salt, file.N, file.R, file.P, scryptKeyLen)
+	if err != nil {
+		return nil, fmt.Errorf("派生密钥失败: %v", err)
+	}
+
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(nonce) != gcm.NonceSize() {
+		return nil, fmt.Errorf("加密密钥文件nonce长度错误")
+	}
+
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return nil, fmt.Errorf("解密失败，口令错误或文件已损坏")
+	}
+	return plaintext, nil
+}
+
+// newGCM 创建 AES-GCM 加密器
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("创建加密器失败: %v", err)
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("创建加密器失败: %v", err)
+	}
+	return gcm, nil
+}
+
+// ParseEnv 解析 KEY=VALUE 格式内容，忽略空行和注释
+func ParseEnv(content string) map[string]string {
+	values := make(map[string]string)
+	lines := strings.Split(content, "\n")
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.SplitN(line, "=", 2)
+		if len(parts) == 2 {
+			values[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
+		}
+	}
+	return values
+}
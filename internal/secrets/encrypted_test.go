@@ -0,0 +1,167 @@
+package secrets
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testSecrets = "# 测试密钥\nPRIVATE_KEYS=0xabc,0xdef\n\nSEPOLIA_RPC_URL = https://rpc.example/key=1\n"
+
+func TestEncryptDecryptRoundTrip(t *testing.T) {
+	content, err := Encrypt([]byte(testSecrets), "secretpw")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(content), "0xabc") {
+		t.Fatalf("加密文件中包含明文")
+	}
+
+	plaintext, err := Decrypt(content, "secretpw")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(plaintext) != testSecrets {
+		t.Errorf("解密结果为 %q，期望 %q", plaintext, testSecrets)
+	}
+
+	// 每次加密使用新的盐和随机数
+	again, err := Encrypt([]byte(testSecrets), "secretpw")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(again) == string(content) {
+		t.Errorf("两次加密结果相同")
+	}
+}
+
+func TestDecryptErrors(t *testing.T) {
+	content, err := Encrypt([]byte(testSecrets), "secretpw")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var file encryptedFile
+	if err := json.Unmarshal(content, &file); err != nil {
+		t.Fatal(err)
+	}
+	modified := func(modify func(*encryptedFile)) []byte {
+		f := file
+		modify(&f)
+		data, err := json.Marshal(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return data
+	}
+
+	tests := []struct {
+		name       string
+		content    []byte
+		passphrase string
+		wantErr    string
+	}{
+		{"口令错误", content, "wrong", "口令错误"},
+		{"密文被篡改", modified(func(f *encryptedFile) { f.Ciphertext = flipFirst(f.Ciphertext) }), "secretpw", "口令错误或文件已损坏"},
+		{"不支持的版本", modified(func(f *encryptedFile) { f.Version = 2 }), "secretpw", "不支持的加密密钥文件格式"},
+		{"nonce无效", modified(func(f *encryptedFile) { f.Nonce = "!" }), "secretpw", "nonce无效"},
+		{"不是JSON", []byte("PRIVATE_KEYS=0xabc"), "secretpw", "解析加密密钥文件失败"},
+	}
+	for _, tt := range tests {
+		_, err := Decrypt(tt.content, tt.passphrase)
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("%s: 错误为 %v，期望包含 %q", tt.name, err, tt.wantErr)
+		}
+	}
+
+	if _, err := Encrypt([]byte(testSecrets), ""); err == nil {
+		t.Errorf("空口令应报错")
+	}
+}
+
+// flipFirst 替换 base64 字符串的首字符
+func flipFirst(s string) string {
+	if s[0] == 'A' {
+		return "B" + s[1:]
+	}
+	return "A" + s[1:]
+}
+
+func TestParseEnv(t *testing.T) {
+	values := ParseEnv(testSecrets)
+	want := map[string]string{
+		"PRIVATE_KEYS":    "0xabc,0xdef",
+		"SEPOLIA_RPC_URL": "https://rpc.example/key=1",
+	}
+	if len(values) != len(want) {
+		t.Errorf("解析得到 %v，期望 %v", values, want)
+	}
+	for key, value := range want {
+		if values[key] != value {
+			t.Errorf("%s = %q，期望 %q", key, values[key], value)
+		}
+	}
+}
+
+func TestEncryptedFileProvider(t *testing.T) {
+	content, err := Encrypt([]byte(testSecrets), "secretpw")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "secrets.enc")
+	if err := os.WriteFile(path, content, 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("SECRETS_PASSPHRASE", "secretpw")
+
+	provider := NewEncryptedFileProvider(path)
+	value, found, err := provider.Lookup("PRIVATE_KEYS")
+	if err != nil || !found || value != "0xabc,0xdef" {
+		t.Errorf("Lookup(PRIVATE_KEYS) = %q, %v, %v", value, found, err)
+	}
+	if _, found, err := provider.Lookup("MISSING"); found || err != nil {
+		t.Errorf("Lookup(MISSING) = %v, %v", found, err)
+	}
+
+	// 口令错误时返回解密错误
+	t.Setenv("SECRETS_PASSPHRASE", "wrong")
+	if _, _, err := NewEncryptedFileProvider(path).Lookup("PRIVATE_KEYS"); err == nil {
+		t.Errorf("口令错误时应报错")
+	}
+
+	// 文件不存在时视为未配置
+	missing := NewEncryptedFileProvider(filepath.Join(t.TempDir(), "missing.enc"))
+	if _, found, err := missing.Lookup("PRIVATE_KEYS"); found || err != nil {
+		t.Errorf("文件不存在: found=%v err=%v", found, err)
+	}
+}
+
+func TestResolverOrder(t *testing.T) {
+	keyFile := filepath.Join(t.TempDir(), "key")
+	if err := os.WriteFile(keyFile, []byte("from-file\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	resolver := NewResolver(EnvProvider{}, FileProvider{}, CommandProvider{})
+
+	t.Setenv("TEST_SECRET_CMD", "echo from-command")
+	if value, source, err := resolver.Source("TEST_SECRET"); err != nil || value != "from-command" || source != "命令输出" {
+		t.Errorf("命令输出: %q %q %v", value, source, err)
+	}
+
+	t.Setenv("TEST_SECRET_FILE", keyFile)
+	if value, source, err := resolver.Source("TEST_SECRET"); err != nil || value != "from-file" || source != "文件引用" {
+		t.Errorf("文件引用: %q %q %v", value, source, err)
+	}
+
+	t.Setenv("TEST_SECRET", "from-env")
+	if value, source, err := resolver.Source("TEST_SECRET"); err != nil || value != "from-env" || source != "环境变量" {
+		t.Errorf("环境变量: %q %q %v", value, source, err)
+	}
+
+	// 文件引用指向不存在的文件时返回错误，而不是继续查找
+	t.Setenv("OTHER_SECRET_FILE", filepath.Join(t.TempDir(), "missing"))
+	if _, err := resolver.Get("OTHER_SECRET"); err == nil {
+		t.Errorf("文件不存在时应报错")
+	}
+}
@@ -0,0 +1,228 @@
+package secrets
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 密钥来源后缀约定：KEY_FILE 指向文件，KEY_CMD 为输出密钥的命令
+const (
+	fileSuffix    = "_FILE"
+	commandSuffix = "_CMD"
+)
+
+// commandTimeout 密钥命令的执行超时时间
+const commandTimeout = 30 * time.Second
+
+// Provider 密钥来源
+type Provider interface {
+	// Name 来源名称，用于诊断输出
+	Name() string
+	// Lookup 查找密钥，未找到时返回 false
+	Lookup(key string) (string, bool, error)
+}
+
+// Resolver 按顺序从多个来源查找密钥
+type Resolver struct {
+	providers []Provider
+}
+
+// NewResolver 创建密钥解析器，按传入顺序查找
+func NewResolver(providers ...Provider) *Resolver {
+	return &Resolver{providers: providers}
+}
+
+// Get 获取密钥，所有来源都未找到时返回空字符串
+func (r *Resolver) Get(key string) (string, error) {
+	value, _, err := r.Source(key)
+	return value, err
+}
+
+// Source 获取密钥及其来源名称
+func (r *Resolver) Source(key string) (string, string, error) {
+	for _, provider := range r.providers {
+		value, found, err := provider.Lookup(key)
+		if err != nil {
+			return "", provider.Name(), fmt.Errorf("从%s读取 %s 失败: %v", provider.Name(), key, err)
+		}
+		if found {
+			return value, provider.Name(), nil
+		}
+	}
+	return "", "", nil
+}
+
+var (
+	defaultResolver     *Resolver
+	defaultResolverOnce sync.Once
+)
+
+// Default 获取默认密钥解析器
+// 查找顺序：环境变量 > KEY_FILE 文件引用 > KEY_CMD 命令输出 > .env 文件 > 加密密钥文件
+// 显式配置的文件引用和命令优先于 .env 中的值，避免模板中的占位值遮盖真实密钥
+func Default() *Resolver {
+	defaultResolverOnce.Do(func() {
+		defaultResolver = NewResolver(
+			EnvProvider{},
+			FileProvider{},
+			CommandProvider{},
+			DotenvProvider{},
+			NewEncryptedFileProvider(EncryptedFilePath()),
+		)
+	})
+	return defaultResolver
+}
+
+// Get 从默认解析器获取密钥
+func Get(key string) (string, error) {
+	return Default().Get(key)
+}
+
+// dotenvValues 从 .env 文件设置到环境变量的值，键为变量名
+var dotenvValues sync.Map
+
+// LoadEnvFile 将 .env 文件中的变量设置到环境变量（已存在的不覆盖），并记录为 .env 来源
+func LoadEnvFile(path string) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return
+	}
+	for key, value := range ParseEnv(string(content)) {
+		if os.Getenv(key) == "" {
+			os.Setenv(key, value)
+			dotenvValues.Store(key, value)
+		}
+	}
+}
+
+// fromDotenv 环境变量的当前值是否来自 .env 文件
+func fromDotenv(key, value string) bool {
+	loaded, ok := dotenvValues.Load(key)
+	return ok && loaded.(string) == value
+}
+
+// EnvProvider 从环境变量读取密钥，不包括从 .env 文件加载的值
+type EnvProvider struct{}
+
+// Name 来源名称
+func (EnvProvider) Name() string { return "环境变量" }
+
+// Lookup 查找密钥
+func (EnvProvider) Lookup(key string) (string, bool, error) {
+	value := strings.TrimSpace(os.Getenv(key))
+	if value == "" || fromDotenv(key, os.Getenv(key)) {
+		return "", false, nil
+	}
+	return value, true, nil
+}
+
+// DotenvProvider 读取从 .env 文件加载的密钥
+type DotenvProvider struct{}
+
+// Name 来源名称
+func (DotenvProvider) Name() string { return ".env文件" }
+
+// Lookup 查找密钥
+func (DotenvProvider) Lookup(key string) (string, bool, error) {
+	value := os.Getenv(key)
+	if !fromDotenv(key, value) {
+		return "", false, nil
+	}
+	value = strings.TrimSpace(value)
+	return value, value != "", nil
+}
+
+// FileProvider 从 KEY_FILE 指向的文件读取密钥
+type FileProvider struct{}
+
+// Name 来源名称
+func (FileProvider) Name() string { return "文件引用" }
+
+// Lookup 查找密钥
+func (FileProvider) Lookup(key string) (string, bool, error) {
+	path := strings.TrimSpace(os.Getenv(key + fileSuffix))
+	if path == "" {
+		return "", false, nil
+	}
+
+	content, err := os.ReadFile(ExpandHome(path))
+	if err != nil {
+		return "", false, err
+	}
+	return strings.TrimSpace(string(content)), true, nil
+}
+
+// CommandProvider 执行 KEY_CMD 配置的命令，以其输出作为密钥
+// 同一命令在进程内只执行一次，并发查找等待同一次执行，避免重复触发口令或硬件确认
+type CommandProvider struct{}
+
+// commandResult 一条密钥命令的执行结果
+type commandResult struct {
+	once   sync.Once
+	output string
+	err    error
+}
+
+// commandResults 已执行的密钥命令，键为命令内容
+var commandResults sync.Map
+
+// Name 来源名称
+func (CommandProvider) Name() string { return "命令输出" }
+
+// Lookup 查找密钥
+func (CommandProvider) Lookup(key string) (string, bool, error) {
+	command := strings.TrimSpace(os.Getenv(key + commandSuffix))
+	if command == "" {
+		return "", false, nil
+	}
+
+	cached, _ := commandResults.LoadOrStore(command, &commandResult{})
+	result := cached.(*commandResult)
+	result.once.Do(func() {
+		result.output, result.err = runCommand(command)
+	})
+	if result.err != nil {
+		return "", false, result.err
+	}
+	return result.output, true, nil
+}
+
+// runCommand 通过系统shell执行命令，返回去除首尾空白的输出
+func runCommand(command string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), commandTimeout)
+	defer cancel()
+
+	// 兼容 Windows 和类 Unix 系统
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", command)
+	}
+	cmd.Stderr = os.Stderr
+
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("执行命令失败: %v", err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// ExpandHome 展开路径中的 ~ 为用户主目录
+func ExpandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") && !strings.HasPrefix(path, `~\`) {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, path[1:])
+}
@@ -0,0 +1,98 @@
+package secrets
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// writeDotenv 写入 .env 文件并加载，测试结束后恢复环境变量
+func writeDotenv(t *testing.T, content string, keys ...string) {
+	t.Helper()
+	for _, key := range keys {
+		t.Setenv(key, "")
+	}
+	path := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	LoadEnvFile(path)
+}
+
+// TestDotenvPlaceholderDoesNotShadowFile .env 模板中的占位私钥不能遮盖 PRIVATE_KEYS_FILE
+func TestDotenvPlaceholderDoesNotShadowFile(t *testing.T) {
+	keyFile := filepath.Join(t.TempDir(), "keys.txt")
+	if err := os.WriteFile(keyFile, []byte("0xabc\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	writeDotenv(t, "PRIVATE_KEYS=your_private_key_1,your_private_key_2\nPRIVATE_KEYS_FILE="+keyFile+"\n",
+		"PRIVATE_KEYS", "PRIVATE_KEYS_FILE")
+
+	resolver := NewResolver(EnvProvider{}, FileProvider{}, CommandProvider{}, DotenvProvider{})
+	value, source, err := resolver.Source("PRIVATE_KEYS")
+	if err != nil || value != "0xabc" || source != "文件引用" {
+		t.Errorf("Source(PRIVATE_KEYS) = %q, %q, %v，期望来自文件引用", value, source, err)
+	}
+}
+
+func TestDotenvPrecedence(t *testing.T) {
+	writeDotenv(t, "TEST_DOTENV_SECRET=from-dotenv\nTEST_DOTENV_OTHER=from-dotenv\n",
+		"TEST_DOTENV_SECRET", "TEST_DOTENV_OTHER")
+	resolver := NewResolver(EnvProvider{}, FileProvider{}, CommandProvider{}, DotenvProvider{})
+
+	// 没有文件引用和命令时使用 .env 中的值
+	if value, source, err := resolver.Source("TEST_DOTENV_SECRET"); err != nil || value != "from-dotenv" || source != ".env文件" {
+		t.Errorf(".env: %q %q %v", value, source, err)
+	}
+
+	// 命令优先于 .env
+	t.Setenv("TEST_DOTENV_SECRET_CMD", "echo from-command")
+	if value, source, err := resolver.Source("TEST_DOTENV_SECRET"); err != nil || value != "from-command" || source != "命令输出" {
+		t.Errorf("命令输出: %q %q %v", value, source, err)
+	}
+
+	// 进程环境变量覆盖 .env 加载的值后，优先于命令
+	t.Setenv("TEST_DOTENV_SECRET", "from-env")
+	if value, source, err := resolver.Source("TEST_DOTENV_SECRET"); err != nil || value != "from-env" || source != "环境变量" {
+		t.Errorf("环境变量: %q %q %v", value, source, err)
+	}
+
+	// 已存在的环境变量不会被 .env 覆盖
+	t.Setenv("TEST_DOTENV_EXISTING", "from-env")
+	writeDotenv(t, "TEST_DOTENV_EXISTING=from-dotenv\n")
+	if value, source, err := resolver.Source("TEST_DOTENV_EXISTING"); err != nil || value != "from-env" || source != "环境变量" {
+		t.Errorf("已存在的环境变量: %q %q %v", value, source, err)
+	}
+}
+
+// TestCommandProviderRunsOnce 并发查找同一密钥时命令只执行一次
+func TestCommandProviderRunsOnce(t *testing.T) {
+	counter := filepath.Join(t.TempDir(), "count")
+	t.Setenv("TEST_ONCE_SECRET_CMD", "echo run >> "+counter+" && echo from-command")
+
+	var wg sync.WaitGroup
+	values := make([]string, 10)
+	for i := range values {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			values[i], _ = NewResolver(CommandProvider{}).Get("TEST_ONCE_SECRET")
+		}(i)
+	}
+	wg.Wait()
+
+	for i, value := range values {
+		if value != "from-command" {
+			t.Errorf("第%d次查找得到 %q", i+1, value)
+		}
+	}
+	content, err := os.ReadFile(counter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if runs := strings.Count(string(content), "run"); runs != 1 {
+		t.Errorf("命令执行了 %d 次，期望 1 次", runs)
+	}
+}
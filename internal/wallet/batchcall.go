@@ -0,0 +1,152 @@
+package wallet
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// BalanceResult 批量查询中单个地址的余额
+type BalanceResult struct {
+	Address common.Address
+	Balance *big.Int
+	Err     error
+}
+
+// GasEstimateResult 批量估算中单笔交易的Gas（已包含缓冲）
+type GasEstimateResult struct {
+	Gas uint64
+	Err error
+}
+
+// GetBalances 通过JSON-RPC批量请求查询多个地址的最新余额
+func (m *Manager) GetBalances(ctx context.Context, addresses []common.Address) ([]BalanceResult, error) {
+	balances := make([]hexutil.Big, len(addresses))
+	elems := make([]rpc.BatchElem, len(addresses))
+	for i, address := range addresses {
+		elems[i] = rpc.BatchElem{
+			Method: "eth_getBalance",
+			Args:   []interface{}{address, "latest"},
+			Result: &balances[i],
+		}
+	}
+
+	if err := m.pool.BatchCall(ctx, elems); err != nil {
+		return nil, fmt.Errorf("查询余额失败: %v", err)
+	}
+
+	results := make([]BalanceResult, len(addresses))
+	for i, address := range addresses {
+		results[i].Address = address
+		if elems[i].Error != nil {
+			results[i].Err = fmt.Errorf("查询余额失败: %v", elems[i].Error)
+			continue
+		}
+		results[i].Balance = balances[i].ToInt()
+	}
+	return results, nil
+}
+
+// GetPendingNonces 通过JSON-RPC批量请求查询多个地址的待处理nonce
+func (m *Manager) GetPendingNonces(ctx context.Context, addresses []common.Address) ([]uint64, error) {
+	nonces := make([]hexutil.Uint64, len(addresses))
+	elems := make([]rpc.BatchElem, len(addresses))
+	for i, address := range addresses {
+		elems[i] = rpc.BatchElem{
+			Method: "eth_getTransactionCount",
+			Args:   []interface{}{address, "pending"},
+			Result: &nonces[i],
+		}
+	}
+
+	if err := m.pool.BatchCall(ctx, elems); err != nil {
+		return nil, fmt.Errorf("获取nonce失败: %v", err)
+	}
+
+	results := make([]uint64, len(addresses))
+	for i := range elems {
+		if elems[i].Error != nil {
+			return nil, fmt.Errorf("获取 %s 的nonce失败: %v", addresses[i].Hex(), elems[i].Error)
+		}
+		results[i] = uint64(nonces[i])
+	}
+	return results, nil
+}
+
+// GetReceipts 通过JSON-RPC批量请求查询多笔交易的收据，尚未打包的交易对应 nil
+func (m *Manager) GetReceipts(ctx context.Context, hashes []common.Hash) ([]*types.Receipt, error) {
+	receipts := make([]*types.Receipt, len(hashes))
+	elems := make([]rpc.BatchElem, len(hashes))
+	for i, hash := range hashes {
+		elems[i] = rpc.BatchElem{
+			Method: "eth_getTransactionReceipt",
+			Args:   []interface{}{hash},
+			Result: &receipts[i],
+		}
+	}
+
+	if err := m.pool.BatchCall(ctx, elems); err != nil {
+		return nil, fmt.Errorf("查询交易收据失败: %v", err)
+	}
+
+	for i := range elems {
+		if elems[i].Error != nil {
+			return nil, fmt.Errorf("查询交易 %s 的收据失败: %v", hashes[i].Hex(), elems[i].Error)
+		}
+	}
+	return receipts, nil
+}
+
+// EstimateGasBatch 通过JSON-RPC批量请求估算多笔交易的Gas，每笔按网络配置增加缓冲
+func (m *Manager) EstimateGasBatch(ctx context.Context, msgs []ethereum.CallMsg) ([]GasEstimateResult, error) {
+	gases := make([]hexutil.Uint64, len(msgs))
+	elems := make([]rpc.BatchElem, len(msgs))
+	for i, msg := range msgs {
+		elems[i] = rpc.BatchElem{
+			Method: "eth_estimateGas",
+			Args:   []interface{}{toCallArg(msg)},
+			Result: &gases[i],
+		}
+	}
+
+	if err := m.pool.BatchCall(ctx, elems); err != nil {
+		return nil, fmt.Errorf("估算Gas失败: %v", err)
+	}
+
+	results := make([]GasEstimateResult, len(msgs))
+	for i := range elems {
+		if elems[i].Error != nil {
+			results[i].Err = fmt.Errorf("估算Gas失败: %v", elems[i].Error)
+			continue
+		}
+		results[i].Gas = m.applyGasBuffer(uint64(gases[i]))
+	}
+	return results, nil
+}
+
+// toCallArg 将CallMsg转换为JSON-RPC调用参数
+func toCallArg(msg ethereum.CallMsg) interface{} {
+	arg := map[string]interface{}{
+		"from": msg.From,
+		"to":   msg.To,
+	}
+	if len(msg.Data) > 0 {
+		arg["input"] = hexutil.Bytes(msg.Data)
+	}
+	if msg.Value != nil {
+		arg["value"] = (*hexutil.Big)(msg.Value)
+	}
+	if msg.Gas != 0 {
+		arg["gas"] = hexutil.Uint64(msg.Gas)
+	}
+	if msg.GasPrice != nil {
+		arg["gasPrice"] = (*hexutil.Big)(msg.GasPrice)
+	}
+	return arg
+}
@@ -0,0 +1,108 @@
+package wallet
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// HeaderByNumber 获取指定区块头，number 为空表示最新区块
+func (m *Manager) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	var header *types.Header
+	err := m.pool.Do(ctx, func(ctx context.Context, client *ethclient.Client) (err error) {
+		header, err = client.HeaderByNumber(ctx, number)
+		return err
+	})
+	if err != nil {
+		if number != nil {
+			return nil, fmt.Errorf("获取区块 %s 失败: %w", number.String(), err)
+		}
+		return nil, fmt.Errorf("获取最新区块失败: %w", err)
+	}
+	return header, nil
+}
+
+// SubscribeNewHead 订阅新区块，需要配置 ws:// 或 wss:// RPC节点
+func (m *Manager) SubscribeNewHead(ctx context.Context, ch chan<- *types.Header) (ethereum.Subscription, error) {
+	return m.pool.SubscribeNewHead(ctx, ch)
+}
+
+// BlockAtTime 二分查找时间戳不晚于 t 的最后一个区块
+func (m *Manager) BlockAtTime(ctx context.Context, t time.Time) (*types.Header, error) {
+	latest, err := m.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	target := uint64(t.Unix())
+	if target >= latest.Time {
+		return latest, nil
+	}
+
+	genesis, err := m.HeaderByNumber(ctx, big.NewInt(0))
+	if err != nil {
+		return nil, err
+	}
+	if target < genesis.Time {
+		return nil, fmt.Errorf("时间 %s 早于创世区块 (%s)",
+			t.Format("2006-01-02 15:04:05"), time.Unix(int64(genesis.Time), 0).Format("2006-01-02 15:04:05"))
+	}
+
+	// 不变式: lo.Time <= target < hi.Time
+	lo, hi := genesis, latest
+	for hi.Number.Uint64()-lo.Number.Uint64() > 1 {
+		mid := (lo.Number.Uint64() + hi.Number.Uint64()) / 2
+		header, err := m.HeaderByNumber(ctx, new(big.Int).SetUint64(mid))
+		if err != nil {
+			return nil, err
+		}
+		if header.Time <= target {
+			lo = header
+		} else {
+			hi = header
+		}
+	}
+	return lo, nil
+}
+
+// CheckHistoricalState 检查节点是否保留指定区块的状态，非归档节点通常只保留最近128个区块
+func (m *Manager) CheckHistoricalState(ctx context.Context, number *big.Int) error {
+	latest, err := m.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if number.Cmp(latest.Number) > 0 {
+		return fmt.Errorf("区块 %s 尚未产生（最新区块 %s）", number.String(), latest.Number.String())
+	}
+
+	err = m.pool.Do(ctx, func(ctx context.Context, client *ethclient.Client) error {
+		_, err := client.BalanceAt(ctx, Multicall3Address, number)
+		return err
+	})
+	if err != nil {
+		if isMissingStateError(err) {
+			return fmt.Errorf("RPC节点没有区块 %s 的历史状态，查询历史余额需要归档节点(archive node): %v", number.String(), err)
+		}
+		return fmt.Errorf("查询区块 %s 的状态失败: %w", number.String(), err)
+	}
+	return nil
+}
+
+// isMissingStateError 判断是否为非归档节点缺少历史状态的错误
+func isMissingStateError(err error) bool {
+	msg := strings.ToLower(err.Error())
+	for _, pattern := range []string{
+		"missing trie node", "historical state", "state is not available", "state not available",
+		"header not found", "pruned", "archive", "state unavailable", "old data not available",
+	} {
+		if strings.Contains(msg, pattern) {
+			return true
+		}
+	}
+	return false
+}
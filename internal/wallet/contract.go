@@ -0,0 +1,212 @@
+package wallet
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// ParseMethodSignature 解析函数签名，如 transfer(address,uint256)；
+// 可在后面附加返回类型用于解码结果，如 balanceOf(address)(uint256) 或 balanceOf(address) returns (uint256)
+func ParseMethodSignature(signature string) (*abi.Method, error) {
+	signature = strings.Join(strings.Fields(signature), "")
+	open := strings.Index(signature, "(")
+	if open <= 0 {
+		return nil, fmt.Errorf("无效的函数签名: %s（格式如 transfer(address,uint256)）", signature)
+	}
+	end := closingParen(signature, open)
+	if end < 0 {
+		return nil, fmt.Errorf("无效的函数签名: %s（括号不匹配）", signature)
+	}
+
+	selector, err := abi.ParseSelector(signature[:end+1])
+	if err != nil {
+		return nil, fmt.Errorf("无效的函数签名: %v", err)
+	}
+
+	var outputs []abi.ArgumentMarshaling
+	if rest := strings.TrimPrefix(signature[end+1:], "returns"); rest != "" {
+		if !strings.HasPrefix(rest, "(") || closingParen(rest, 0) != len(rest)-1 {
+			return nil, fmt.Errorf("无效的返回类型: %s（格式如 (uint256)）", rest)
+		}
+		returns, err := abi.ParseSelector("returns" + rest)
+		if err != nil {
+			return nil, fmt.Errorf("无效的返回类型: %v", err)
+		}
+		outputs = returns.Inputs
+	}
+
+	// 借助ABI JSON构造方法，签名中不包含可变性，按 payable 处理
+	definition, err := json.Marshal([]map[string]interface{}{{
+		"type":            "function",
+		"name":            selector.Name,
+		"inputs":          selector.Inputs,
+		"outputs":         outputs,
+		"stateMutability": "payable",
+	}})
+	if err != nil {
+		return nil, fmt.Errorf("无效的函数签名: %v", err)
+	}
+	parsed, err := abi.JSON(strings.NewReader(string(definition)))
+	if err != nil {
+		return nil, fmt.Errorf("无效的函数签名: %v", err)
+	}
+	method := parsed.Methods[selector.Name]
+	return &method, nil
+}
+
+// closingParen 返回与 open 位置左括号匹配的右括号位置，未匹配时返回 -1
+func closingParen(s string, open int) int {
+	depth := 0
+	for i := open; i < len(s); i++ {
+		switch s[i] {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// LoadABIFile 读取ABI文件：纯ABI数组，或包含 abi 字段的编译产物（Hardhat、Foundry、Truffle）
+func LoadABIFile(path string) (*abi.ABI, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取ABI文件失败: %v", err)
+	}
+
+	definition := content
+	var artifact struct {
+		ABI json.RawMessage `json:"abi"`
+	}
+	if json.Unmarshal(content, &artifact) == nil && len(artifact.ABI) > 0 {
+		definition = artifact.ABI
+	}
+
+	parsed, err := abi.JSON(strings.NewReader(string(definition)))
+	if err != nil {
+		return nil, fmt.Errorf("解析ABI文件 %s 失败: %v", path, err)
+	}
+	return &parsed, nil
+}
+
+// LoadBytecodeFile 读取合约字节码：十六进制文本（可带0x前缀），或编译产物中的 bytecode 字段
+// （Hardhat、Truffle 为字符串，Foundry 为 {"object": ...}）
+func LoadBytecodeFile(path string) ([]byte, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取字节码文件失败: %v", err)
+	}
+
+	code := strings.TrimSpace(string(content))
+	var artifact struct {
+		Bytecode json.RawMessage `json:"bytecode"`
+	}
+	if json.Unmarshal(content, &artifact) == nil && len(artifact.Bytecode) > 0 {
+		var object struct {
+			Object string `json:"object"`
+		}
+		if json.Unmarshal(artifact.Bytecode, &code) != nil {
+			if err := json.Unmarshal(artifact.Bytecode, &object); err != nil {
+				return nil, fmt.Errorf("解析字节码文件 %s 失败: 无效的 bytecode 字段", path)
+			}
+			code = object.Object
+		}
+	}
+
+	code = strings.TrimPrefix(strings.TrimPrefix(code, "0x"), "0X")
+	if strings.Contains(code, "__") {
+		return nil, fmt.Errorf("字节码文件 %s 包含未链接的库占位符，请先链接库地址", path)
+	}
+	bytecode, err := hex.DecodeString(code)
+	if err != nil {
+		return nil, fmt.Errorf("解析字节码文件 %s 失败: %v", path, err)
+	}
+	if len(bytecode) == 0 {
+		return nil, fmt.Errorf("字节码文件 %s 为空", path)
+	}
+	return bytecode, nil
+}
+
+// FindMethod 在ABI中按名称或完整签名（如 transfer(address,uint256)）查找方法
+// 重载方法按名称查找时报错，需要使用完整签名
+func FindMethod(parsed *abi.ABI, name string) (*abi.Method, error) {
+	name = strings.Join(strings.Fields(name), "")
+	if strings.Contains(name, "(") {
+		for _, method := range parsed.Methods {
+			if method.Sig == name {
+				return &method, nil
+			}
+		}
+		return nil, fmt.Errorf("ABI中没有方法 %s", name)
+	}
+
+	var matches []abi.Method
+	for _, method := range parsed.Methods {
+		if method.RawName == name {
+			matches = append(matches, method)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("ABI中没有方法 %s", name)
+	case 1:
+		return &matches[0], nil
+	}
+	sigs := make([]string, len(matches))
+	for i, method := range matches {
+		sigs[i] = method.Sig
+	}
+	return nil, fmt.Errorf("方法 %s 有多个重载，请使用完整签名: %s", name, strings.Join(sigs, ", "))
+}
+
+// CallContract 执行只读调用（eth_call），block 为空表示最新区块；执行回滚时返回包含回滚原因的错误
+func (m *Manager) CallContract(ctx context.Context, msg ethereum.CallMsg, block *big.Int) ([]byte, error) {
+	var result []byte
+	err := m.pool.Do(ctx, func(ctx context.Context, client *ethclient.Client) (err error) {
+		result, err = client.CallContract(ctx, msg, block)
+		return err
+	})
+	if err != nil {
+		if reason, ok := revertReason(err); ok {
+			return nil, fmt.Errorf("执行回滚: %s", reason)
+		}
+		return nil, fmt.Errorf("调用合约失败: %v", err)
+	}
+	return result, nil
+}
+
+// revertReason 从 eth_call 错误中解析回滚原因：Error(string) 返回原因文字，自定义错误返回原始数据
+func revertReason(err error) (string, bool) {
+	var dataErr rpc.DataError
+	if !errors.As(err, &dataErr) {
+		return "", false
+	}
+	data, ok := dataErr.ErrorData().(string)
+	if !ok {
+		return "", false
+	}
+	raw, decodeErr := hexutil.Decode(data)
+	if decodeErr != nil || len(raw) == 0 {
+		return "", false
+	}
+	if reason, unpackErr := abi.UnpackRevert(raw); unpackErr == nil {
+		return reason, true
+	}
+	return fmt.Sprintf("自定义错误 %s", data), true
+}
@@ -0,0 +1,89 @@
+package wallet
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// DiagnosedMethods 诊断时探测的可选RPC方法
+var DiagnosedMethods = []string{"eth_feeHistory", "eth_maxPriorityFeePerGas"}
+
+// RPCDiagnosis 单个RPC节点的诊断结果
+type RPCDiagnosis struct {
+	RPCSource
+
+	Reachable     bool
+	Error         error
+	Latency       time.Duration
+	ChainID       *big.Int
+	ExpectedChain *big.Int
+	LatestBlock   uint64
+	BlockAge      time.Duration
+	ClientVersion string
+	EIP1559       bool
+	Methods       map[string]bool
+}
+
+// ChainIDMatches 节点链ID是否与期望一致
+func (d RPCDiagnosis) ChainIDMatches() bool {
+	return d.ChainID != nil && d.ExpectedChain != nil && d.ChainID.Cmp(d.ExpectedChain) == 0
+}
+
+// DiagnoseRPC 诊断RPC节点：连通性、链ID、最新区块、客户端版本、EIP-1559及可选方法支持
+func DiagnoseRPC(ctx context.Context, source RPCSource, expectedChainID *big.Int) RPCDiagnosis {
+	result := RPCDiagnosis{
+		RPCSource:     source,
+		ExpectedChain: expectedChainID,
+		Methods:       make(map[string]bool),
+	}
+
+	rpcClient, err := rpc.DialContext(ctx, source.URL)
+	if err != nil {
+		result.Error = fmt.Errorf("连接失败: %v", err)
+		return result
+	}
+	defer rpcClient.Close()
+	client := ethclient.NewClient(rpcClient)
+
+	// 链ID（同时用于测量延迟和连通性）
+	start := time.Now()
+	chainID, err := client.ChainID(ctx)
+	result.Latency = time.Since(start)
+	if err != nil {
+		result.Error = fmt.Errorf("eth_chainId 失败: %v", err)
+		return result
+	}
+	result.Reachable = true
+	result.ChainID = chainID
+
+	// 最新区块及其时间
+	header, err := client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		result.Error = fmt.Errorf("获取最新区块失败: %v", err)
+	} else {
+		result.LatestBlock = header.Number.Uint64()
+		result.BlockAge = time.Since(time.Unix(int64(header.Time), 0))
+		result.EIP1559 = header.BaseFee != nil
+	}
+
+	// 客户端版本（部分服务商不支持）
+	var version string
+	if err := rpcClient.CallContext(ctx, &version, "web3_clientVersion"); err == nil {
+		result.ClientVersion = version
+	}
+
+	// 可选方法支持情况
+	var feeHistory interface{}
+	result.Methods["eth_feeHistory"] = rpcClient.CallContext(ctx, &feeHistory, "eth_feeHistory",
+		hexutil.Uint(1), "latest", []float64{50}) == nil
+	var tip hexutil.Big
+	result.Methods["eth_maxPriorityFeePerGas"] = rpcClient.CallContext(ctx, &tip, "eth_maxPriorityFeePerGas") == nil
+
+	return result
+}
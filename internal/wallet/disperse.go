@@ -0,0 +1,64 @@
+package wallet
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// DefaultDisperseAddress Disperse 合约（disperse.app）地址，在多数EVM链上相同
+var DefaultDisperseAddress = common.HexToAddress("0xD152f549545093347A162Dce210e7293f1452150")
+
+// disperseABIJSON Disperse 合约中用到的方法
+const disperseABIJSON = `[
+	{"type":"function","name":"disperseEther","stateMutability":"payable","inputs":[{"name":"recipients","type":"address[]"},{"name":"values","type":"uint256[]"}],"outputs":[]},
+	{"type":"function","name":"disperseToken","stateMutability":"nonpayable","inputs":[{"name":"token","type":"address"},{"name":"recipients","type":"address[]"},{"name":"values","type":"uint256[]"}],"outputs":[]}
+]`
+
+var disperseABI = mustParseABI(disperseABIJSON)
+
+// PackDisperse 编码一次向多个接收方的转账：token 为空时调用 disperseEther（交易金额为合计），
+// 否则调用 disperseToken（合约通过 transferFrom 从发送方转出，需事先授权）
+func PackDisperse(token *TokenInfo, recipients []common.Address, values []*big.Int) ([]byte, error) {
+	if len(recipients) != len(values) {
+		return nil, fmt.Errorf("接收方数量 %d 与金额数量 %d 不一致", len(recipients), len(values))
+	}
+	var data []byte
+	var err error
+	if token == nil {
+		data, err = disperseABI.Pack("disperseEther", recipients, values)
+	} else {
+		data, err = disperseABI.Pack("disperseToken", token.Address, recipients, values)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("编码批量转账失败: %v", err)
+	}
+	return data, nil
+}
+
+// GetAllowance 查询 owner 授权给 spender 的代币额度
+func (m *Manager) GetAllowance(ctx context.Context, token *TokenInfo, owner, spender common.Address) (*big.Int, error) {
+	data, err := ERC20ABI.Pack("allowance", owner, spender)
+	if err != nil {
+		return nil, fmt.Errorf("编码授权查询失败: %v", err)
+	}
+
+	var result []byte
+	err = m.pool.Do(ctx, func(ctx context.Context, client *ethclient.Client) (err error) {
+		result, err = client.CallContract(ctx, ethereum.CallMsg{To: &token.Address, Data: data}, nil)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("查询 %s 授权额度失败: %v", token.Symbol, err)
+	}
+
+	values, err := ERC20ABI.Unpack("allowance", result)
+	if err != nil || len(values) == 0 {
+		return nil, fmt.Errorf("查询 %s 授权额度失败: 返回值无效", token.Symbol)
+	}
+	return values[0].(*big.Int), nil
+}
@@ -0,0 +1,324 @@
+package wallet
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// Gas策略
+const (
+	GasSlow     = "slow"
+	GasStandard = "standard"
+	GasFast     = "fast"
+	GasCustom   = "custom"
+)
+
+// DefaultGasLimitBuffer 估算Gas后默认增加的缓冲百分比
+const DefaultGasLimitBuffer = 20
+
+// feeHistoryBlocks 计算建议费用时参考的最近区块数
+const feeHistoryBlocks = 20
+
+// gasPreset 预设策略参数
+type gasPreset struct {
+	percentile     float64 // 优先费取最近区块内交易小费的百分位
+	baseFeePercent int64   // maxFeePerGas = 下一区块 baseFee × baseFeePercent% + 优先费
+	legacyPercent  int64   // 传统网络: gasPrice = 节点建议价格 × legacyPercent%
+}
+
+// gasPresets 预设策略：baseFee 倍数决定 maxFee 能承受的 baseFee 上涨幅度（每个满块最多上涨12.5%）
+var gasPresets = map[string]gasPreset{
+	GasSlow:     {percentile: 10, baseFeePercent: 125, legacyPercent: 90},
+	GasStandard: {percentile: 50, baseFeePercent: 150, legacyPercent: 100},
+	GasFast:     {percentile: 90, baseFeePercent: 200, legacyPercent: 125},
+}
+
+// feeHistoryPercentiles eth_feeHistory 请求的百分位，与预设策略一一对应
+var feeHistoryPercentiles = []float64{10, 50, 90}
+
+// GasOptions Gas策略选项
+type GasOptions struct {
+	Strategy    string
+	MaxFee      *big.Int // custom 策略的 maxFeePerGas，传统网络为 gasPrice
+	PriorityFee *big.Int // custom 策略的 maxPriorityFeePerGas，为空时使用节点建议值
+}
+
+// ParseGasOptions 解析Gas策略及自定义费用（单位 Gwei）
+// 未指定策略时，填写了 maxFee 视为 custom，否则为 standard
+func ParseGasOptions(strategy, maxFeeGwei, priorityFeeGwei string) (GasOptions, error) {
+	strategy = strings.ToLower(strings.TrimSpace(strategy))
+	maxFeeGwei = strings.TrimSpace(maxFeeGwei)
+	priorityFeeGwei = strings.TrimSpace(priorityFeeGwei)
+
+	if strategy == "" {
+		strategy = GasStandard
+		if maxFeeGwei != "" {
+			strategy = GasCustom
+		}
+	}
+
+	opts := GasOptions{Strategy: strategy}
+	if strategy != GasCustom {
+		if _, ok := gasPresets[strategy]; !ok {
+			return opts, fmt.Errorf("无效的Gas策略: %s (可选: slow, standard, fast, custom)", strategy)
+		}
+		if maxFeeGwei != "" || priorityFeeGwei != "" {
+			return opts, fmt.Errorf("只有 custom 策略可以指定 max fee 和 priority fee")
+		}
+		return opts, nil
+	}
+
+	if maxFeeGwei == "" {
+		return opts, fmt.Errorf("custom 策略需要指定 max fee (Gwei)")
+	}
+	maxFee, err := ParseUnits(maxFeeGwei, 9)
+	if err != nil {
+		return opts, fmt.Errorf("无效的 max fee: %v", err)
+	}
+	opts.MaxFee = maxFee
+
+	if priorityFeeGwei != "" {
+		priorityFee, err := ParseUnits(priorityFeeGwei, 9)
+		if err != nil {
+			return opts, fmt.Errorf("无效的 priority fee: %v", err)
+		}
+		if priorityFee.Cmp(maxFee) > 0 {
+			return opts, fmt.Errorf("priority fee 不能高于 max fee")
+		}
+		opts.PriorityFee = priorityFee
+	}
+	return opts, nil
+}
+
+// GasFees 交易的费用参数，EIP-1559 网络使用 MaxFeePerGas/MaxPriorityFeePerGas，传统网络使用 GasPrice
+type GasFees struct {
+	Strategy             string
+	BaseFee              *big.Int // 下一区块的 baseFee，传统网络为空
+	GasPrice             *big.Int
+	MaxFeePerGas         *big.Int
+	MaxPriorityFeePerGas *big.Int
+	Capped               bool // 费用被网络 max_fee_gwei 上限截断
+}
+
+// Dynamic 是否为 EIP-1559 交易
+func (f *GasFees) Dynamic() bool {
+	return f.MaxFeePerGas != nil
+}
+
+// FeeCap 每单位Gas的最高费用
+func (f *GasFees) FeeCap() *big.Int {
+	if f.Dynamic() {
+		return f.MaxFeePerGas
+	}
+	return f.GasPrice
+}
+
+// MaxCost 按最高费用计算的手续费上限
+func (f *GasFees) MaxCost(gasLimit uint64) *big.Int {
+	return new(big.Int).Mul(f.FeeCap(), new(big.Int).SetUint64(gasLimit))
+}
+
+// FormatGwei 将 Wei 格式化为 Gwei，保留到 1 Wei 精度并去掉末尾的0
+func FormatGwei(wei *big.Int) string {
+	return FormatUnitsExact(wei, 9)
+}
+
+// SuggestFees 按Gas策略计算交易费用，并应用网络配置的 max_fee_gwei 上限
+func (m *Manager) SuggestFees(ctx context.Context, opts GasOptions) (*GasFees, error) {
+	if opts.Strategy == "" {
+		opts.Strategy = GasStandard
+	}
+	if opts.Strategy == GasCustom && opts.MaxFee == nil {
+		return nil, fmt.Errorf("custom 策略需要指定 max fee (Gwei)")
+	}
+	if opts.Strategy != GasCustom {
+		if _, ok := gasPresets[opts.Strategy]; !ok {
+			return nil, fmt.Errorf("无效的Gas策略: %s", opts.Strategy)
+		}
+	}
+
+	if m.config.EIP1559 {
+		fees, err := m.dynamicFees(ctx, opts)
+		if err != errNoBaseFee {
+			return fees, err
+		}
+		// 节点不返回 baseFee 时按传统交易处理
+	}
+	return m.legacyFees(ctx, opts)
+}
+
+// errNoBaseFee 节点未返回 baseFee，链不支持 EIP-1559
+var errNoBaseFee = fmt.Errorf("节点未返回 baseFee")
+
+// dynamicFees 根据 eth_feeHistory 计算 EIP-1559 费用
+func (m *Manager) dynamicFees(ctx context.Context, opts GasOptions) (*GasFees, error) {
+	baseFee, tips, err := m.feeHistory(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	fees := &GasFees{Strategy: opts.Strategy, BaseFee: baseFee}
+	if opts.Strategy == GasCustom {
+		fees.MaxFeePerGas = new(big.Int).Set(opts.MaxFee)
+		fees.MaxPriorityFeePerGas = opts.PriorityFee
+		if fees.MaxPriorityFeePerGas == nil {
+			if fees.MaxPriorityFeePerGas, err = m.suggestTip(ctx); err != nil {
+				return nil, err
+			}
+		}
+		if fees.MaxFeePerGas.Cmp(baseFee) < 0 {
+			return nil, fmt.Errorf("max fee %s Gwei 低于当前 base fee %s Gwei，交易无法打包",
+				FormatGwei(fees.MaxFeePerGas), FormatGwei(baseFee))
+		}
+	} else {
+		preset := gasPresets[opts.Strategy]
+		tip := tips[opts.Strategy]
+		if tip == nil {
+			// 最近区块没有交易时使用节点建议的小费
+			if tip, err = m.suggestTip(ctx); err != nil {
+				return nil, err
+			}
+		}
+		fees.MaxPriorityFeePerGas = tip
+		fees.MaxFeePerGas = new(big.Int).Mul(baseFee, big.NewInt(preset.baseFeePercent))
+		fees.MaxFeePerGas.Div(fees.MaxFeePerGas, big.NewInt(100))
+		fees.MaxFeePerGas.Add(fees.MaxFeePerGas, tip)
+	}
+
+	if err := m.applyFeeCap(fees, fees.MaxFeePerGas, baseFee); err != nil {
+		return nil, err
+	}
+	if fees.Capped {
+		fees.MaxFeePerGas = new(big.Int).Set(m.config.MaxFeeCap)
+	}
+	if fees.MaxPriorityFeePerGas.Cmp(fees.MaxFeePerGas) > 0 {
+		fees.MaxPriorityFeePerGas = new(big.Int).Set(fees.MaxFeePerGas)
+	}
+	return fees, nil
+}
+
+// legacyFees 根据节点建议价格计算传统交易的 gasPrice
+func (m *Manager) legacyFees(ctx context.Context, opts GasOptions) (*GasFees, error) {
+	fees := &GasFees{Strategy: opts.Strategy}
+	if opts.Strategy == GasCustom {
+		fees.GasPrice = new(big.Int).Set(opts.MaxFee)
+		if err := m.applyFeeCap(fees, fees.GasPrice, nil); err != nil {
+			return nil, err
+		}
+		return fees, nil
+	}
+
+	suggested, err := m.GetGasPrice(ctx)
+	if err != nil {
+		return nil, err
+	}
+	fees.GasPrice = new(big.Int).Mul(suggested, big.NewInt(gasPresets[opts.Strategy].legacyPercent))
+	fees.GasPrice.Div(fees.GasPrice, big.NewInt(100))
+
+	// 节点建议价格已超过上限时，截断后的价格也难以打包
+	if err := m.applyFeeCap(fees, fees.GasPrice, suggested); err != nil {
+		return nil, err
+	}
+	if fees.Capped {
+		fees.GasPrice = new(big.Int).Set(m.config.MaxFeeCap)
+	}
+	return fees, nil
+}
+
+// applyFeeCap 检查网络 max_fee_gwei 上限：自定义费用或市场价格（floor）超过上限时报错，预设策略超过上限时标记截断
+func (m *Manager) applyFeeCap(fees *GasFees, feeCap, floor *big.Int) error {
+	limit := m.config.MaxFeeCap
+	if limit == nil || feeCap.Cmp(limit) <= 0 {
+		return nil
+	}
+	if fees.Strategy == GasCustom {
+		return fmt.Errorf("自定义费用 %s Gwei 超过网络 %s 的上限 %s Gwei (max_fee_gwei)",
+			FormatGwei(feeCap), m.network, FormatGwei(limit))
+	}
+	if floor != nil && floor.Cmp(limit) > 0 {
+		return fmt.Errorf("当前Gas价格 %s Gwei 超过网络 %s 的上限 %s Gwei (max_fee_gwei)，请稍后再试或调整上限",
+			FormatGwei(floor), m.network, FormatGwei(limit))
+	}
+	fees.Capped = true
+	return nil
+}
+
+// feeHistory 查询最近区块的费用历史，返回下一区块的 baseFee 和各预设策略的优先费（中位数）
+// 节点不支持 eth_feeHistory 时退回最新区块的 baseFee，优先费为空
+func (m *Manager) feeHistory(ctx context.Context) (*big.Int, map[string]*big.Int, error) {
+	var history *ethereum.FeeHistory
+	err := m.pool.Do(ctx, func(ctx context.Context, client *ethclient.Client) (err error) {
+		history, err = client.FeeHistory(ctx, feeHistoryBlocks, nil, feeHistoryPercentiles)
+		return err
+	})
+	if err != nil || len(history.BaseFee) == 0 {
+		if ctx.Err() != nil {
+			return nil, nil, fmt.Errorf("查询费用历史失败: %v", ctx.Err())
+		}
+		header, err := m.HeaderByNumber(ctx, nil)
+		if err != nil {
+			return nil, nil, err
+		}
+		if header.BaseFee == nil {
+			return nil, nil, errNoBaseFee
+		}
+		return header.BaseFee, map[string]*big.Int{}, nil
+	}
+
+	// BaseFee 比区块数多一个，最后一个为下一区块的 baseFee
+	baseFee := history.BaseFee[len(history.BaseFee)-1]
+	if baseFee == nil {
+		return nil, nil, errNoBaseFee
+	}
+
+	tips := make(map[string]*big.Int, len(gasPresets))
+	for strategy, preset := range gasPresets {
+		column := -1
+		for i, percentile := range feeHistoryPercentiles {
+			if percentile == preset.percentile {
+				column = i
+			}
+		}
+
+		// 空区块的小费为0，不参与统计
+		var samples []*big.Int
+		for i, rewards := range history.Reward {
+			if i < len(history.GasUsedRatio) && history.GasUsedRatio[i] == 0 {
+				continue
+			}
+			if column < len(rewards) && rewards[column] != nil {
+				samples = append(samples, rewards[column])
+			}
+		}
+		if len(samples) == 0 {
+			continue
+		}
+		sort.Slice(samples, func(i, j int) bool { return samples[i].Cmp(samples[j]) < 0 })
+		tips[strategy] = new(big.Int).Set(samples[len(samples)/2])
+	}
+	return baseFee, tips, nil
+}
+
+// suggestTip 查询节点建议的优先费 (eth_maxPriorityFeePerGas)
+func (m *Manager) suggestTip(ctx context.Context) (*big.Int, error) {
+	var tip *big.Int
+	err := m.pool.Do(ctx, func(ctx context.Context, client *ethclient.Client) (err error) {
+		tip, err = client.SuggestGasTipCap(ctx)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("获取优先费失败: %w", err)
+	}
+	return tip, nil
+}
+
+// applyGasBuffer 按网络配置的缓冲百分比放大Gas估算值
+func (m *Manager) applyGasBuffer(gas uint64) uint64 {
+	return gas * uint64(100+m.config.GasLimitBuffer) / 100
+}
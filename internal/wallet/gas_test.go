@@ -0,0 +1,178 @@
+package wallet
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// fakeFeeNode 模拟返回固定费用数据的节点（eth 命名空间）
+type fakeFeeNode struct {
+	baseFees     []int64   // Gwei，最后一个为下一区块的 baseFee
+	rewards      [][]int64 // Gwei，每个区块按 feeHistoryPercentiles 的小费
+	gasUsedRatio []float64
+	gasPrice     int64 // Gwei
+	tip          int64 // Gwei
+}
+
+type fakeFeeHistory struct {
+	OldestBlock  *hexutil.Big     `json:"oldestBlock"`
+	Reward       [][]*hexutil.Big `json:"reward,omitempty"`
+	BaseFee      []*hexutil.Big   `json:"baseFeePerGas,omitempty"`
+	GasUsedRatio []float64        `json:"gasUsedRatio"`
+}
+
+func (n *fakeFeeNode) FeeHistory(blockCount hexutil.Uint, lastBlock rpc.BlockNumber, percentiles []float64) (*fakeFeeHistory, error) {
+	history := &fakeFeeHistory{OldestBlock: (*hexutil.Big)(big.NewInt(100)), GasUsedRatio: n.gasUsedRatio}
+	for _, fee := range n.baseFees {
+		history.BaseFee = append(history.BaseFee, (*hexutil.Big)(gwei(fee)))
+	}
+	for _, rewards := range n.rewards {
+		row := make([]*hexutil.Big, len(rewards))
+		for i, reward := range rewards {
+			row[i] = (*hexutil.Big)(gwei(reward))
+		}
+		history.Reward = append(history.Reward, row)
+	}
+	return history, nil
+}
+
+func (n *fakeFeeNode) GasPrice() *hexutil.Big {
+	return (*hexutil.Big)(gwei(n.gasPrice))
+}
+
+func (n *fakeFeeNode) MaxPriorityFeePerGas() *hexutil.Big {
+	return (*hexutil.Big)(gwei(n.tip))
+}
+
+func gwei(n int64) *big.Int {
+	return new(big.Int).Mul(big.NewInt(n), big.NewInt(1e9))
+}
+
+// newFeeTestManager 创建连接到模拟节点的钱包管理器，maxFeeGwei 为 0 表示不限制
+func newFeeTestManager(t *testing.T, node *fakeFeeNode, eip1559 bool, maxFeeGwei int64) *Manager {
+	t.Helper()
+	server := rpc.NewServer()
+	if err := server.RegisterName("eth", node); err != nil {
+		t.Fatal(err)
+	}
+	client := rpc.DialInProc(server)
+	t.Cleanup(func() {
+		client.Close()
+		server.Stop()
+	})
+
+	ep := &rpcEndpoint{url: "fake", client: ethclient.NewClient(client), rpc: client, healthy: true}
+	config := NetworkConfig{Name: "Fake", EIP1559: eip1559}
+	if maxFeeGwei > 0 {
+		config.MaxFeeCap = gwei(maxFeeGwei)
+	}
+	return &Manager{
+		pool:    &RPCPool{endpoints: []*rpcEndpoint{ep}, callTimeout: defaultCallTimeout, lastCheck: time.Now()},
+		network: "fake",
+		config:  config,
+	}
+}
+
+// feeHistoryNode 下一区块 baseFee 100 Gwei；空区块不参与统计，各百分位小费中位数为 2/3/5 Gwei
+func feeHistoryNode() *fakeFeeNode {
+	return &fakeFeeNode{
+		baseFees:     []int64{90, 95, 100, 100, 100},
+		rewards:      [][]int64{{1, 2, 3}, {2, 3, 5}, {50, 50, 50}, {3, 4, 8}},
+		gasUsedRatio: []float64{0.5, 0.9, 0, 0.6},
+		gasPrice:     10,
+		tip:          1,
+	}
+}
+
+func TestSuggestFeesDynamic(t *testing.T) {
+	tests := []struct {
+		name       string
+		opts       GasOptions
+		maxFeeGwei int64
+		wantMax    int64 // Gwei
+		wantTip    int64 // Gwei
+		wantCapped bool
+		wantErr    bool
+	}{
+		{name: "slow", opts: GasOptions{Strategy: GasSlow}, wantMax: 127, wantTip: 2},
+		{name: "默认 standard", opts: GasOptions{}, wantMax: 153, wantTip: 3},
+		{name: "fast", opts: GasOptions{Strategy: GasFast}, wantMax: 205, wantTip: 5},
+		{name: "上限内不截断", opts: GasOptions{Strategy: GasStandard}, maxFeeGwei: 160, wantMax: 153, wantTip: 3},
+		{name: "超过上限截断", opts: GasOptions{Strategy: GasFast}, maxFeeGwei: 160, wantMax: 160, wantTip: 5, wantCapped: true},
+		{name: "baseFee 超过上限", opts: GasOptions{Strategy: GasSlow}, maxFeeGwei: 99, wantErr: true},
+		{name: "custom", opts: GasOptions{Strategy: GasCustom, MaxFee: gwei(120), PriorityFee: gwei(7)}, wantMax: 120, wantTip: 7},
+		{name: "custom 使用节点建议小费", opts: GasOptions{Strategy: GasCustom, MaxFee: gwei(120)}, wantMax: 120, wantTip: 1},
+		{name: "custom 低于 baseFee", opts: GasOptions{Strategy: GasCustom, MaxFee: gwei(90)}, wantErr: true},
+		{name: "custom 超过上限", opts: GasOptions{Strategy: GasCustom, MaxFee: gwei(170)}, maxFeeGwei: 160, wantErr: true},
+		{name: "无效策略", opts: GasOptions{Strategy: "turbo"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		wm := newFeeTestManager(t, feeHistoryNode(), true, tt.maxFeeGwei)
+		fees, err := wm.SuggestFees(context.Background(), tt.opts)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("%s: 期望报错，得到 maxFee %s Gwei", tt.name, FormatGwei(fees.MaxFeePerGas))
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if !fees.Dynamic() || fees.BaseFee.Cmp(gwei(100)) != 0 {
+			t.Errorf("%s: 应为 EIP-1559 费用且 baseFee 为 100 Gwei", tt.name)
+		}
+		if fees.MaxFeePerGas.Cmp(gwei(tt.wantMax)) != 0 || fees.MaxPriorityFeePerGas.Cmp(gwei(tt.wantTip)) != 0 {
+			t.Errorf("%s: maxFee %s / tip %s Gwei，期望 %d / %d", tt.name,
+				FormatGwei(fees.MaxFeePerGas), FormatGwei(fees.MaxPriorityFeePerGas), tt.wantMax, tt.wantTip)
+		}
+		if fees.Capped != tt.wantCapped {
+			t.Errorf("%s: Capped = %v，期望 %v", tt.name, fees.Capped, tt.wantCapped)
+		}
+	}
+}
+
+func TestSuggestFeesLegacy(t *testing.T) {
+	tests := []struct {
+		name       string
+		opts       GasOptions
+		maxFeeGwei int64
+		want       *big.Int
+		wantCapped bool
+		wantErr    bool
+	}{
+		{name: "slow", opts: GasOptions{Strategy: GasSlow}, want: gwei(9)},
+		{name: "standard", opts: GasOptions{Strategy: GasStandard}, want: gwei(10)},
+		{name: "fast", opts: GasOptions{Strategy: GasFast}, want: big.NewInt(12.5e9)},
+		{name: "超过上限截断", opts: GasOptions{Strategy: GasFast}, maxFeeGwei: 11, want: gwei(11), wantCapped: true},
+		{name: "节点价格超过上限", opts: GasOptions{Strategy: GasStandard}, maxFeeGwei: 9, wantErr: true},
+		{name: "custom", opts: GasOptions{Strategy: GasCustom, MaxFee: gwei(20)}, want: gwei(20)},
+		{name: "custom 超过上限", opts: GasOptions{Strategy: GasCustom, MaxFee: gwei(20)}, maxFeeGwei: 11, wantErr: true},
+	}
+	for _, tt := range tests {
+		wm := newFeeTestManager(t, feeHistoryNode(), false, tt.maxFeeGwei)
+		fees, err := wm.SuggestFees(context.Background(), tt.opts)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("%s: 期望报错，得到 gasPrice %s Gwei", tt.name, FormatGwei(fees.GasPrice))
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if fees.Dynamic() || fees.GasPrice.Cmp(tt.want) != 0 {
+			t.Errorf("%s: gasPrice %s Gwei，期望 %s", tt.name, FormatGwei(fees.GasPrice), FormatGwei(tt.want))
+		}
+		if fees.Capped != tt.wantCapped {
+			t.Errorf("%s: Capped = %v，期望 %v", tt.name, fees.Capped, tt.wantCapped)
+		}
+	}
+}
@@ -0,0 +1,303 @@
+package wallet
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// historyLogRange 单次 eth_getLogs 查询的最大区块数，服务商拒绝时自动减半
+const historyLogRange = 2000
+
+// TransferRecord 钱包的一条转账记录：原生代币转账（含钱包发出的合约调用）或 ERC-20 Transfer 事件
+type TransferRecord struct {
+	Block    uint64   `json:"block"`
+	Time     uint64   `json:"time"`
+	TxHash   string   `json:"tx_hash"`
+	TxIndex  uint     `json:"tx_index"`
+	LogIndex int      `json:"log_index"`       // 原生代币转账为 -1
+	Token    string   `json:"token,omitempty"` // 代币合约地址，原生代币为空
+	Symbol   string   `json:"symbol"`
+	Decimals int      `json:"decimals"`
+	From     string   `json:"from"`
+	To       string   `json:"to,omitempty"` // 部署合约时为空
+	Value    *big.Int `json:"value"`
+	Fee      *big.Int `json:"fee,omitempty"` // 发送方支付的手续费，仅原生代币记录
+	Failed   bool     `json:"failed,omitempty"`
+}
+
+// rpcHistoryBlock eth_getBlockByNumber 返回的区块（仅解析需要的字段）
+type rpcHistoryBlock struct {
+	Number       hexutil.Uint64 `json:"number"`
+	Timestamp    hexutil.Uint64 `json:"timestamp"`
+	Transactions []struct {
+		Hash             common.Hash     `json:"hash"`
+		TransactionIndex hexutil.Uint    `json:"transactionIndex"`
+		From             common.Address  `json:"from"`
+		To               *common.Address `json:"to"`
+		Value            *hexutil.Big    `json:"value"`
+		GasPrice         *hexutil.Big    `json:"gasPrice"`
+	} `json:"transactions"`
+}
+
+// ScanHistory 扫描区块范围内指定地址的原生代币转账和 ERC-20 转账，按区块和交易顺序排序
+// 原生代币转账通过逐块读取交易获得，合约内部转账（internal transaction）不在此列
+// progress 在每批区块扫描完成后调用，可为空
+func (m *Manager) ScanHistory(ctx context.Context, addresses []common.Address, from, to uint64, progress func(scanned, total uint64)) ([]TransferRecord, error) {
+	if from > to {
+		return nil, nil
+	}
+	watched := make(map[common.Address]bool, len(addresses))
+	for _, address := range addresses {
+		watched[address] = true
+	}
+
+	// 原生代币：批量读取区块及交易
+	var records []TransferRecord
+	var hashes []common.Hash
+	var gasPrices []*big.Int
+	blockTimes := make(map[uint64]uint64)
+	total := to - from + 1
+	for start := from; start <= to; start += maxBatchSize {
+		end := start + maxBatchSize - 1
+		if end > to || end < start {
+			end = to
+		}
+
+		blocks := make([]*rpcHistoryBlock, end-start+1)
+		elems := make([]rpc.BatchElem, len(blocks))
+		for i := range elems {
+			elems[i] = rpc.BatchElem{
+				Method: "eth_getBlockByNumber",
+				Args:   []interface{}{hexutil.EncodeUint64(start + uint64(i)), true},
+				Result: &blocks[i],
+			}
+		}
+		if err := m.pool.BatchCall(ctx, elems); err != nil {
+			return nil, fmt.Errorf("读取区块 %d-%d 失败: %v", start, end, err)
+		}
+
+		for i, block := range blocks {
+			if elems[i].Error != nil {
+				return nil, fmt.Errorf("读取区块 %d 失败: %v", start+uint64(i), elems[i].Error)
+			}
+			if block == nil {
+				return nil, fmt.Errorf("区块 %d 不存在", start+uint64(i))
+			}
+			blockTimes[uint64(block.Number)] = uint64(block.Timestamp)
+
+			for _, tx := range block.Transactions {
+				value := (*big.Int)(tx.Value)
+				if value == nil {
+					value = new(big.Int)
+				}
+				// 钱包发出的所有交易都会扣除手续费；转入只记录金额大于0的交易
+				inbound := tx.To != nil && watched[*tx.To] && value.Sign() > 0
+				if !watched[tx.From] && !inbound {
+					continue
+				}
+
+				record := TransferRecord{
+					Block:    uint64(block.Number),
+					Time:     uint64(block.Timestamp),
+					TxHash:   tx.Hash.Hex(),
+					TxIndex:  uint(tx.TransactionIndex),
+					LogIndex: -1,
+					Symbol:   m.Symbol(),
+					Decimals: m.config.Decimals,
+					From:     tx.From.Hex(),
+					Value:    value,
+				}
+				if tx.To != nil {
+					record.To = tx.To.Hex()
+				}
+				records = append(records, record)
+				hashes = append(hashes, tx.Hash)
+				gasPrices = append(gasPrices, (*big.Int)(tx.GasPrice))
+			}
+		}
+
+		if progress != nil {
+			progress(end-from+1, total)
+		}
+		if end == to {
+			break
+		}
+	}
+
+	// 收据：执行结果和实际手续费
+	if len(hashes) > 0 {
+		receipts, err := m.GetReceipts(ctx, hashes)
+		if err != nil {
+			return nil, err
+		}
+		for i, receipt := range receipts {
+			if receipt == nil {
+				continue
+			}
+			records[i].Failed = receipt.Status == types.ReceiptStatusFailed
+			// 失败的交易不转移金额
+			if records[i].Failed {
+				records[i].Value = new(big.Int)
+			}
+			price := receipt.EffectiveGasPrice
+			if price == nil {
+				price = gasPrices[i]
+			}
+			if price != nil && watched[common.HexToAddress(records[i].From)] {
+				records[i].Fee = new(big.Int).Mul(price, new(big.Int).SetUint64(receipt.GasUsed))
+			}
+		}
+	}
+
+	// ERC-20：按发送方和接收方分别查询 Transfer 事件
+	tokenRecords, err := m.scanTokenTransfers(ctx, addresses, from, to, blockTimes)
+	if err != nil {
+		return nil, err
+	}
+	records = append(records, tokenRecords...)
+
+	SortTransferRecords(records)
+	return records, nil
+}
+
+// SortTransferRecords 按区块、交易和日志顺序排序
+func SortTransferRecords(records []TransferRecord) {
+	sort.SliceStable(records, func(i, j int) bool {
+		a, b := records[i], records[j]
+		if a.Block != b.Block {
+			return a.Block < b.Block
+		}
+		if a.TxIndex != b.TxIndex {
+			return a.TxIndex < b.TxIndex
+		}
+		return a.LogIndex < b.LogIndex
+	})
+}
+
+// scanTokenTransfers 查询区块范围内涉及指定地址的 ERC-20 Transfer 事件
+func (m *Manager) scanTokenTransfers(ctx context.Context, addresses []common.Address, from, to uint64, blockTimes map[uint64]uint64) ([]TransferRecord, error) {
+	event := ERC20ABI.Events["Transfer"].ID
+	topics := make([]common.Hash, len(addresses))
+	for i, address := range addresses {
+		topics[i] = common.BytesToHash(address.Bytes())
+	}
+
+	var logs []types.Log
+	for _, query := range [][][]common.Hash{
+		{{event}, topics},      // 转出
+		{{event}, nil, topics}, // 转入
+	} {
+		found, err := m.filterLogs(ctx, ethereum.FilterQuery{Topics: query}, from, to)
+		if err != nil {
+			return nil, err
+		}
+		logs = append(logs, found...)
+	}
+
+	var records []TransferRecord
+	seen := make(map[string]bool)
+	tokens := make(map[common.Address]*TokenInfo)
+	for _, log := range logs {
+		// ERC-721 的 Transfer 事件 tokenId 为 indexed 参数（4个topic），不在此列
+		if log.Removed || len(log.Topics) != 3 || len(log.Data) != 32 {
+			continue
+		}
+		key := fmt.Sprintf("%s-%d", log.TxHash.Hex(), log.Index)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		info, ok := tokens[log.Address]
+		if !ok {
+			var err error
+			if info, err = m.GetTokenInfo(ctx, log.Address); err != nil {
+				info = &TokenInfo{Address: log.Address, Symbol: shortAddress(log.Address)}
+			}
+			tokens[log.Address] = info
+		}
+
+		records = append(records, TransferRecord{
+			Block:    log.BlockNumber,
+			Time:     blockTimes[log.BlockNumber],
+			TxHash:   log.TxHash.Hex(),
+			TxIndex:  log.TxIndex,
+			LogIndex: int(log.Index),
+			Token:    log.Address.Hex(),
+			Symbol:   info.Symbol,
+			Decimals: info.Decimals,
+			From:     common.BytesToAddress(log.Topics[1].Bytes()).Hex(),
+			To:       common.BytesToAddress(log.Topics[2].Bytes()).Hex(),
+			Value:    new(big.Int).SetBytes(log.Data),
+		})
+	}
+	return records, nil
+}
+
+// filterLogs 按 historyLogRange 分段查询日志，节点拒绝（范围过大、结果过多）时将该段减半重试
+func (m *Manager) filterLogs(ctx context.Context, query ethereum.FilterQuery, from, to uint64) ([]types.Log, error) {
+	var logs []types.Log
+	for start := from; start <= to; start += historyLogRange {
+		end := start + historyLogRange - 1
+		if end > to || end < start {
+			end = to
+		}
+		found, err := m.filterLogRange(ctx, query, start, end)
+		if err != nil {
+			return nil, err
+		}
+		logs = append(logs, found...)
+		if end == to {
+			break
+		}
+	}
+	return logs, nil
+}
+
+// filterLogRange 查询单段日志，失败时二分
+func (m *Manager) filterLogRange(ctx context.Context, query ethereum.FilterQuery, from, to uint64) ([]types.Log, error) {
+	return bisectLogRange(ctx, query, from, to, func(ctx context.Context, query ethereum.FilterQuery) (logs []types.Log, err error) {
+		err = m.pool.Do(ctx, func(ctx context.Context, client *ethclient.Client) (err error) {
+			logs, err = client.FilterLogs(ctx, query)
+			return err
+		})
+		return logs, err
+	})
+}
+
+// logFetcher 查询 query 指定区块范围的日志
+type logFetcher func(ctx context.Context, query ethereum.FilterQuery) ([]types.Log, error)
+
+// bisectLogRange 查询 from-to 的日志，节点拒绝时（如结果过多）将范围一分为二分别查询
+func bisectLogRange(ctx context.Context, query ethereum.FilterQuery, from, to uint64, fetch logFetcher) ([]types.Log, error) {
+	query.FromBlock = new(big.Int).SetUint64(from)
+	query.ToBlock = new(big.Int).SetUint64(to)
+	logs, err := fetch(ctx, query)
+	if err == nil {
+		return logs, nil
+	}
+	// 节点返回的错误（如结果过多、limit exceeded）缩小范围重试，网络故障直接返回
+	if from == to || ctx.Err() != nil || (isTransientError(err) && !isJSONRPCError(err)) {
+		return nil, fmt.Errorf("查询区块 %d-%d 的日志失败: %v", from, to, err)
+	}
+
+	mid := from + (to-from)/2
+	left, err := bisectLogRange(ctx, query, from, mid, fetch)
+	if err != nil {
+		return nil, err
+	}
+	right, err := bisectLogRange(ctx, query, mid+1, to, fetch)
+	if err != nil {
+		return nil, err
+	}
+	return append(left, right...), nil
+}
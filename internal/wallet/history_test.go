@@ -0,0 +1,108 @@
+package wallet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// fakeLogNode 模拟限制单次返回日志数量的节点
+type fakeLogNode struct {
+	blocks []uint64 // 每个日志所在区块
+	limit  int
+	err    error // 不为空时每次查询都返回该错误
+	calls  []string
+}
+
+func (n *fakeLogNode) fetch(ctx context.Context, query ethereum.FilterQuery) ([]types.Log, error) {
+	from, to := query.FromBlock.Uint64(), query.ToBlock.Uint64()
+	n.calls = append(n.calls, fmt.Sprintf("%d-%d", from, to))
+	if n.err != nil {
+		return nil, n.err
+	}
+	var logs []types.Log
+	for _, block := range n.blocks {
+		if block >= from && block <= to {
+			logs = append(logs, types.Log{BlockNumber: block})
+		}
+	}
+	if len(logs) > n.limit {
+		return nil, jsonRPCError{-32005, fmt.Sprintf("query returned more than %d results", n.limit)}
+	}
+	return logs, nil
+}
+
+func logBlocks(logs []types.Log) string {
+	blocks := make([]string, len(logs))
+	for i, log := range logs {
+		blocks[i] = fmt.Sprint(log.BlockNumber)
+	}
+	return strings.Join(blocks, ",")
+}
+
+func TestBisectLogRange(t *testing.T) {
+	tests := []struct {
+		name      string
+		node      *fakeLogNode
+		from, to  uint64
+		want      string // 日志所在区块
+		wantCalls string
+		wantErr   bool
+	}{
+		{
+			name: "无需二分", node: &fakeLogNode{blocks: []uint64{5, 40}, limit: 2},
+			from: 0, to: 63, want: "5,40", wantCalls: "0-63",
+		},
+		{
+			name: "结果过多时二分并保持顺序", node: &fakeLogNode{blocks: []uint64{5, 10, 11, 12, 40}, limit: 2},
+			from: 0, to: 63, want: "5,10,11,12,40",
+			wantCalls: "0-63,0-31,0-15,0-7,8-15,8-11,12-15,16-31,32-63",
+		},
+		{
+			name: "单个区块仍超出限制", node: &fakeLogNode{blocks: []uint64{7, 7, 7}, limit: 2},
+			from: 4, to: 7, wantErr: true,
+			wantCalls: "4-7,4-5,6-7,6-6,7-7",
+		},
+		{
+			name: "网络错误不二分", node: &fakeLogNode{err: &net.OpError{Op: "dial", Err: errors.New("connection refused")}},
+			from: 0, to: 63, wantErr: true, wantCalls: "0-63",
+		},
+		{
+			name: "节点拒绝时二分到单个区块", node: &fakeLogNode{err: jsonRPCError{-32000, "block range too large"}},
+			from: 0, to: 1, wantErr: true, wantCalls: "0-1,0-0",
+		},
+	}
+	for _, tt := range tests {
+		logs, err := bisectLogRange(context.Background(), ethereum.FilterQuery{}, tt.from, tt.to, tt.node.fetch)
+		if calls := strings.Join(tt.node.calls, ","); calls != tt.wantCalls {
+			t.Errorf("%s: 查询范围 %s，期望 %s", tt.name, calls, tt.wantCalls)
+		}
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("%s: 期望报错", tt.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if got := logBlocks(logs); got != tt.want {
+			t.Errorf("%s: 日志区块 %s，期望 %s", tt.name, got, tt.want)
+		}
+	}
+
+	// 调用方取消后不再二分
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	node := &fakeLogNode{blocks: []uint64{1, 2, 3}, limit: 2}
+	if _, err := bisectLogRange(ctx, ethereum.FilterQuery{}, 0, 3, node.fetch); err == nil || len(node.calls) != 1 {
+		t.Errorf("取消后: err=%v 查询 %v", err, node.calls)
+	}
+}
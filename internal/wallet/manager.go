@@ -7,11 +7,16 @@ import (
 	"io/ioutil"
 	"math/big"
 	"os"
+	"strconv"
 	"strings"
+	"time"
+
+	"transfer-tool/internal/secrets"
 
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethclient"
 	"gopkg.in/yaml.v3"
@@ -21,45 +26,9 @@ import (
 type Manager struct {
 	privateKeys []*ecdsa.PrivateKey
 	addresses   []common.Address
-	client      *ethclient.Client
+	pool        *RPCPool
 	network     string
-}
-
-// NetworkConfig 网络配置
-type NetworkConfig struct {
-	RPCURL   string
-	ChainID  *big.Int
-	Name     string
-	Explorer string
-}
-
-// 默认网络配置（仅包含链ID和名称，RPC URL从配置文件读取）
-var defaultNetworkConfigs = map[string]NetworkConfig{
-	"sepolia": {
-		ChainID:  big.NewInt(11155111),
-		Name:     "Sepolia",
-		Explorer: "https://sepolia.etherscan.io/tx/",
-	},
-	"goerli": {
-		ChainID:  big.NewInt(5),
-		Name:     "Goerli",
-		Explorer: "https://goerli.etherscan.io/tx/",
-	},
-	"mainnet": {
-		ChainID:  big.NewInt(1),
-		Name:     "Mainnet",
-		Explorer: "https://etherscan.io/tx/",
-	},
-	"bnb": {
-		ChainID:  big.NewInt(56),
-		Name:     "BNB Smart Chain",
-		Explorer: "https://bscscan.com/tx/",
-	},
-	"polygon": {
-		ChainID:  big.NewInt(137),
-		Name:     "Polygon",
-		Explorer: "https://polygonscan.com/tx/",
-	},
+	config      NetworkConfig
 }
 
 // NewManager 创建钱包管理器
@@ -71,71 +40,103 @@ func NewManager(envFile, network string) (*Manager, error) {
 
 // NewManagerWithRPC 创建钱包管理器（支持自定义RPC）
 func NewManagerWithRPC(envFile, network string, customRPCs map[string]string) (*Manager, error) {
+	network = NormalizeNetworkName(network)
+
 	// 自动加载环境变量
 	loadEnvFile(envFile)
 
 	// 加载私钥
-	privateKeys, err := loadPrivateKeys(envFile)
+	keys, err := newKeyManager()
 	if err != nil {
-		return nil, fmt.Errorf("加载私钥失败: %v", err)
+		return nil, err
 	}
 
-	// 推导地址
-	addresses := make([]common.Address, len(privateKeys))
-	for i, pk := range privateKeys {
-		addresses[i] = crypto.PubkeyToAddress(pk.PublicKey)
+	// 检查网络是否支持（内置网络 + 配置文件 networks 部分，auto 表示由节点链ID推导）
+	var networkConfig NetworkConfig
+	if network != AutoNetwork {
+		networkConfig, err = GetNetwork(network)
+		if err != nil {
+			return nil, err
+		}
 	}
 
-	// 检查网络是否支持
-	if _, exists := defaultNetworkConfigs[network]; !exists {
-		return nil, fmt.Errorf("不支持的网络: %s", network)
+	// 获取RPC节点列表（优先级：自定义RPC > 环境变量 > 全局配置 > 网络定义）
+	sources, err := ListRPCSources(envFile, network, customRPCs)
+	if err != nil {
+		return nil, err
 	}
-
-	// 获取RPC URL（优先级：自定义RPC > 环境变量 > 全局配置）
-	rpcURL := getRPCURL(envFile, network, customRPCs, "")
-	if rpcURL == "" {
+	if len(sources) == 0 {
 		return nil, fmt.Errorf("未找到网络 %s 的RPC配置，请检查配置文件", network)
 	}
 
-	// 连接以太坊客户端
-	client, err := ethclient.Dial(rpcURL)
+	// 连接所有RPC节点
+	ctx, cancel := context.WithTimeout(context.Background(), chainIDTimeout)
+	defer cancel()
+	pool, err := NewRPCPool(ctx, sources)
+	if err != nil {
+		return nil, err
+	}
+
+	// 查询节点链ID，防止RPC配置错误导致为错误的链签名交易
+	nodeChainID, err := pool.VerifyChainID(ctx)
 	if err != nil {
+		pool.Close()
 		return nil, fmt.Errorf("连接网络失败: %v", err)
 	}
 
-	return &Manager{
-		privateKeys: privateKeys,
-		addresses:   addresses,
-		client:      client,
-		network:     network,
-	}, nil
+	if network == AutoNetwork {
+		network, networkConfig, err = networkByChainID(ctx, pool.Best(), nodeChainID)
+		if err != nil {
+			pool.Close()
+			return nil, err
+		}
+	} else if networkConfig.ChainID.Cmp(nodeChainID) != 0 {
+		pool.Close()
+		return nil, fmt.Errorf("链ID不匹配: 网络 %s 期望 %s，RPC节点 %s 返回 %s，请检查RPC配置",
+			network, networkConfig.ChainID.String(), strings.Join(pool.URLs(), ", "), nodeChainID.String())
+	}
+
+	networkConfig.RPCURL = sources[0].URL
+	networkConfig.RPCURLs = pool.URLs()
+	pool.SetRateLimit(networkConfig.RPCRateLimit)
+
+	keys.pool = pool
+	keys.network = network
+	keys.config = networkConfig
+	return keys, nil
 }
 
-// loadPrivateKeys 从.env文件加载私钥
-func loadPrivateKeys(envFile string) ([]*ecdsa.PrivateKey, error) {
-	// 检查文件是否存在
-	if _, err := os.Stat(envFile); os.IsNotExist(err) {
-		return nil, fmt.Errorf(".env文件不存在: %s", envFile)
-	}
+// NewKeyManager 创建只加载私钥、不连接RPC节点的钱包管理器，供离线签名使用
+// 该管理器没有网络配置，不能查询链上数据或发送交易
+func NewKeyManager(envFile string) (*Manager, error) {
+	loadEnvFile(envFile)
+	return newKeyManager()
+}
 
-	// 读取文件内容
-	content, err := ioutil.ReadFile(envFile)
+// newKeyManager 加载私钥并推导地址
+func newKeyManager() (*Manager, error) {
+	privateKeys, err := loadPrivateKeys()
 	if err != nil {
-		return nil, fmt.Errorf("读取.env文件失败: %v", err)
+		return nil, fmt.Errorf("加载私钥失败: %v", err)
 	}
 
-	// 解析PRIVATE_KEYS
-	lines := strings.Split(string(content), "\n")
-	var privateKeysStr string
-	for _, line := range lines {
-		if strings.HasPrefix(line, "PRIVATE_KEYS=") {
-			privateKeysStr = strings.TrimPrefix(line, "PRIVATE_KEYS=")
-			break
-		}
+	addresses := make([]common.Address, len(privateKeys))
+	for i, pk := range privateKeys {
+		addresses[i] = crypto.PubkeyToAddress(pk.PublicKey)
+	}
+	return &Manager{privateKeys: privateKeys, addresses: addresses}, nil
+}
+
+// loadPrivateKeys 从密钥来源加载私钥
+// 支持 PRIVATE_KEYS（含.env）、PRIVATE_KEYS_FILE、PRIVATE_KEYS_CMD 和加密密钥文件
+func loadPrivateKeys() ([]*ecdsa.PrivateKey, error) {
+	privateKeysStr, err := secrets.Get("PRIVATE_KEYS")
+	if err != nil {
+		return nil, err
 	}
 
 	if privateKeysStr == "" {
-		return nil, fmt.Errorf("未找到PRIVATE_KEYS配置")
+		return nil, fmt.Errorf("未找到PRIVATE_KEYS配置（支持 PRIVATE_KEYS、PRIVATE_KEYS_FILE、PRIVATE_KEYS_CMD 或加密密钥文件）")
 	}
 
 	// 分割私钥
@@ -201,24 +202,89 @@ func (m *Manager) GetPrivateKeyByIndex(index int) *ecdsa.PrivateKey {
 	return m.privateKeys[index%len(m.privateKeys)]
 }
 
-// GetClient 获取以太坊客户端
+// FindWallet 根据索引或地址查找托管钱包，返回钱包索引
+func (m *Manager) FindWallet(spec string) (int, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return 0, fmt.Errorf("未指定钱包")
+	}
+
+	// 纯数字按索引处理
+	if index, err := strconv.Atoi(spec); err == nil {
+		if index < 0 || index >= len(m.addresses) {
+			return 0, fmt.Errorf("钱包索引超出范围: %d (共 %d 个钱包)", index, len(m.addresses))
+		}
+		return index, nil
+	}
+
+	if common.IsHexAddress(spec) {
+		address := common.HexToAddress(spec)
+		for i, addr := range m.addresses {
+			if addr == address {
+				return i, nil
+			}
+		}
+		return 0, fmt.Errorf("地址 %s 不是托管钱包", address.Hex())
+	}
+
+	return 0, fmt.Errorf("无效的钱包标识: %s", spec)
+}
+
+// GetClient 获取当前最健康节点的以太坊客户端
 func (m *Manager) GetClient() *ethclient.Client {
-	return m.client
+	return m.pool.Best()
+}
+
+// GetRPCPool 获取RPC连接池
+func (m *Manager) GetRPCPool() *RPCPool {
+	return m.pool
+}
+
+// Close 关闭所有RPC连接
+func (m *Manager) Close() {
+	if m.pool != nil {
+		m.pool.Close()
+	}
 }
 
 // GetNetworkConfig 获取网络配置
 func (m *Manager) GetNetworkConfig() NetworkConfig {
-	return defaultNetworkConfigs[m.network]
+	return m.config
+}
+
+// GetNetwork 获取网络名称
+func (m *Manager) GetNetwork() string {
+	return m.network
 }
 
 // GetChainID 获取链ID
 func (m *Manager) GetChainID() *big.Int {
-	return defaultNetworkConfigs[m.network].ChainID
+	return m.config.ChainID
 }
 
 // GetExplorerURL 获取区块浏览器URL
 func (m *Manager) GetExplorerURL(txHash string) string {
-	return defaultNetworkConfigs[m.network].Explorer + txHash
+	return m.config.TxURL(txHash)
+}
+
+// GetAddressExplorerURL 获取地址的区块浏览器URL
+func (m *Manager) GetAddressExplorerURL(address string) string {
+	return m.config.AddressURL(address)
+}
+
+// Symbol 获取原生代币符号
+func (m *Manager) Symbol() string {
+	return m.config.Symbol
+}
+
+// FormatNative 按原生代币精度格式化金额
+func (m *Manager) FormatNative(value *big.Int) string {
+	return FormatUnits(value, m.config.Decimals)
+}
+
+// ParseNative 按原生代币精度解析金额
+func (m *Manager) ParseNative(amountStr string) (*big.Int, error) {
+	return ParseUnits(amountStr, m.config.Decimals)
 }
 
 // ValidateAddress 验证以太坊地址格式
@@ -231,66 +297,136 @@ func ValidateAddress(address string) error {
 
 // ParseAmount 解析金额（ETH转Wei）
 func ParseAmount(amountStr string) (*big.Int, error) {
-	amount, ok := new(big.Float).SetString(amountStr)
+	return ParseUnits(amountStr, 18)
+}
+
+// FormatAmount 格式化金额（Wei转ETH）
+func FormatAmount(wei *big.Int) string {
+	return FormatUnits(wei, 18)
+}
+
+// ParseUnits 按指定精度解析金额，如 ParseUnits("1.5", 6) = 1500000
+func ParseUnits(amountStr string, decimals int) (*big.Int, error) {
+	amount, ok := new(big.Float).SetPrec(256).SetString(strings.TrimSpace(amountStr))
 	if !ok {
 		return nil, fmt.Errorf("无效的金额格式: %s", amountStr)
 	}
 
-	if amount.Cmp(big.NewFloat(0)) <= 0 {
+	if amount.Sign() <= 0 {
 		return nil, fmt.Errorf("金额必须大于0")
 	}
 
-	// 转换为Wei (1 ETH = 10^18 Wei)
-	weiFloat := new(big.Float).Mul(amount, big.NewFloat(1e18))
-	wei, _ := weiFloat.Int(nil)
+	// 转换为最小单位 (1 ETH = 10^18 Wei)
+	unit := new(big.Float).SetPrec(256).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil))
+	value, _ := new(big.Float).SetPrec(256).Mul(amount, unit).Int(nil)
 
-	return wei, nil
+	return value, nil
 }
 
-// FormatAmount 格式化金额（Wei转ETH）
-func FormatAmount(wei *big.Int) string {
-	eth := new(big.Float).Quo(new(big.Float).SetInt(wei), big.NewFloat(1e18))
-	return fmt.Sprintf("%.6f", eth)
+// FormatUnits 按指定精度格式化金额，保留6位小数
+func FormatUnits(value *big.Int, decimals int) string {
+	unit := new(big.Float).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil))
+	amount := new(big.Float).Quo(new(big.Float).SetInt(value), unit)
+	return fmt.Sprintf("%.6f", amount)
+}
+
+// FormatUnitsExact 按精度精确格式化金额，去掉小数末尾的0，用于导出和对账
+func FormatUnitsExact(value *big.Int, decimals int) string {
+	unit := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil)
+	whole, frac := new(big.Int).QuoRem(new(big.Int).Abs(value), unit, new(big.Int))
+
+	text := whole.String()
+	if frac.Sign() != 0 {
+		fracText := fmt.Sprintf("%0*s", decimals, frac.String())
+		text += "." + strings.TrimRight(fracText, "0")
+	}
+	if value.Sign() < 0 {
+		text = "-" + text
+	}
+	return text
+}
+
+// SetCallTimeout 设置单次RPC调用的超时时间，0 表示不限制
+func (m *Manager) SetCallTimeout(timeout time.Duration) {
+	m.pool.SetCallTimeout(timeout)
+}
+
+// SetRateLimit 设置每个RPC节点每秒最多请求数，0 表示不限流
+func (m *Manager) SetRateLimit(rps float64) {
+	m.pool.SetRateLimit(rps)
 }
 
 // GetBalance 获取地址余额
-func (m *Manager) GetBalance(address common.Address) (*big.Int, error) {
-	ctx := context.Background()
-	balance, err := m.client.BalanceAt(ctx, address, nil)
+func (m *Manager) GetBalance(ctx context.Context, address common.Address) (*big.Int, error) {
+	var balance *big.Int
+	err := m.pool.Do(ctx, func(ctx context.Context, client *ethclient.Client) (err error) {
+		balance, err = client.BalanceAt(ctx, address, nil)
+		return err
+	})
 	if err != nil {
 		return nil, fmt.Errorf("查询余额失败: %v", err)
 	}
 	return balance, nil
 }
 
+// IsContract 地址上是否部署了合约
+func (m *Manager) IsContract(ctx context.Context, address common.Address) (bool, error) {
+	var code []byte
+	err := m.pool.Do(ctx, func(ctx context.Context, client *ethclient.Client) (err error) {
+		code, err = client.CodeAt(ctx, address, nil)
+		return err
+	})
+	if err != nil {
+		return false, fmt.Errorf("查询合约代码失败: %v", err)
+	}
+	return len(code) > 0, nil
+}
+
 // GetGasPrice 获取当前Gas价格
-func (m *Manager) GetGasPrice() (*big.Int, error) {
-	ctx := context.Background()
-	gasPrice, err := m.client.SuggestGasPrice(ctx)
+func (m *Manager) GetGasPrice(ctx context.Context) (*big.Int, error) {
+	var gasPrice *big.Int
+	err := m.pool.Do(ctx, func(ctx context.Context, client *ethclient.Client) (err error) {
+		gasPrice, err = client.SuggestGasPrice(ctx)
+		return err
+	})
 	if err != nil {
-		return nil, fmt.Errorf("获取Gas价格失败: %v", err)
+		return nil, fmt.Errorf("获取Gas价格失败: %w", err)
 	}
 	return gasPrice, nil
 }
 
 // EstimateGas 估算Gas消耗
-func (m *Manager) EstimateGas(from, to common.Address, value *big.Int, data []byte) (uint64, error) {
-	ctx := context.Background()
-	msg := ethereum.CallMsg{
+func (m *Manager) EstimateGas(ctx context.Context, from, to common.Address, value *big.Int, data []byte) (uint64, error) {
+	return m.estimateGas(ctx, ethereum.CallMsg{
 		From:  from,
 		To:    &to,
 		Value: value,
 		Data:  data,
-	}
+	})
+}
 
-	gasLimit, err := m.client.EstimateGas(ctx, msg)
+// EstimateDeployGas 估算部署合约的Gas消耗，data 为创建代码（字节码加构造参数）
+func (m *Manager) EstimateDeployGas(ctx context.Context, from common.Address, value *big.Int, data []byte) (uint64, error) {
+	return m.estimateGas(ctx, ethereum.CallMsg{
+		From:  from,
+		Value: value,
+		Data:  data,
+	})
+}
+
+// estimateGas 估算Gas消耗并按网络配置增加缓冲
+func (m *Manager) estimateGas(ctx context.Context, msg ethereum.CallMsg) (uint64, error) {
+	var gasLimit uint64
+	err := m.pool.Do(ctx, func(ctx context.Context, client *ethclient.Client) (err error) {
+		gasLimit, err = client.EstimateGas(ctx, msg)
+		return err
+	})
 	if err != nil {
 		return 0, fmt.Errorf("估算Gas失败: %v", err)
 	}
 
-	// 增加20%的缓冲
-	gasLimit = gasLimit * 120 / 100
-	return gasLimit, nil
+	// 按网络配置增加缓冲
+	return m.applyGasBuffer(gasLimit), nil
 }
 
 // CreateTransactor 创建交易发送者
@@ -300,120 +436,160 @@ func (m *Manager) CreateTransactor(privateKey *ecdsa.PrivateKey) *bind.TransactO
 	return auth
 }
 
-// getRPCURL 获取RPC URL，按优先级选择
-func getRPCURL(envFile, network string, customRPCs map[string]string, defaultURL string) string {
-	// 1. 优先使用自定义RPC配置
-	if customRPCs != nil {
-		if customRPC, exists := customRPCs[network]; exists && customRPC != "" {
-			return customRPC
-		}
+// SendTransfer 使用指定钱包签名并发送转账交易，返回交易哈希
+func (m *Manager) SendTransfer(ctx context.Context, index int, to common.Address, value *big.Int, fees *GasFees, gasLimit uint64) (string, error) {
+	if m.GetPrivateKeyByIndex(index) == nil {
+		return "", fmt.Errorf("没有可用的私钥")
 	}
+	from := m.GetAddressByIndex(index)
 
-	// 2. 尝试从环境变量读取
-	envRPC := getRPCFromEnv(envFile, network)
-	if envRPC != "" {
-		return envRPC
+	// 获取nonce
+	var nonce uint64
+	err := m.pool.Do(ctx, func(ctx context.Context, client *ethclient.Client) (err error) {
+		nonce, err = client.PendingNonceAt(ctx, from)
+		return err
+	})
+	if err != nil {
+		return "", fmt.Errorf("获取nonce失败: %v", err)
 	}
 
-	// 3. 尝试从全局RPC配置文件读取
-	globalRPC, err := loadGlobalRPCConfig()
-	if err == nil && globalRPC != nil {
-		if rpcURL, exists := globalRPC[network]; exists && rpcURL != "" {
-			return rpcURL
-		}
-	}
+	return m.SendTransferWithNonce(ctx, index, to, value, fees, gasLimit, nonce)
+}
 
-	// 4. 如果都没有配置，返回错误
-	return ""
+// SendTransferWithNonce 使用指定nonce签名并发送转账交易，供本地维护nonce的批量场景使用
+// 费用为 EIP-1559 参数时发送 DynamicFeeTx，否则发送传统交易
+func (m *Manager) SendTransferWithNonce(ctx context.Context, index int, to common.Address, value *big.Int, fees *GasFees, gasLimit, nonce uint64) (string, error) {
+	return m.SendTxWithNonce(ctx, index, &to, value, nil, fees, gasLimit, nonce)
 }
 
-// getRPCFromEnv 从环境变量文件读取RPC配置
-func getRPCFromEnv(envFile, network string) string {
-	// 检查文件是否存在
-	if _, err := os.Stat(envFile); os.IsNotExist(err) {
-		return ""
+// SendTxWithNonce 使用指定nonce签名并发送任意交易（合约调用带 data，to 为空表示部署合约）
+func (m *Manager) SendTxWithNonce(ctx context.Context, index int, to *common.Address, value *big.Int, data []byte, fees *GasFees, gasLimit, nonce uint64) (string, error) {
+	// 构建并签名交易
+	signedTx, err := m.signTx(index, newTx(to, value, data, fees, gasLimit, nonce, m.GetChainID()))
+	if err != nil {
+		return "", err
 	}
 
-	// 读取文件内容
-	content, err := ioutil.ReadFile(envFile)
+	// 广播到多个节点
+	err = m.pool.Broadcast(ctx, signedTx)
 	if err != nil {
-		return ""
+		return "", fmt.Errorf("发送交易失败: %v", err)
 	}
 
-	// 解析环境变量
-	lines := strings.Split(string(content), "\n")
-	envVars := make(map[string]string)
+	return signedTx.Hash().Hex(), nil
+}
 
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if line == "" || strings.HasPrefix(line, "#") {
-			continue
-		}
+// newTx 按费用类型构建交易，to 为空表示部署合约
+func newTx(to *common.Address, value *big.Int, data []byte, fees *GasFees, gasLimit, nonce uint64, chainID *big.Int) *types.Transaction {
+	if fees.Dynamic() {
+		return types.NewTx(&types.DynamicFeeTx{
+			ChainID:   chainID,
+			Nonce:     nonce,
+			GasTipCap: fees.MaxPriorityFeePerGas,
+			GasFeeCap: fees.MaxFeePerGas,
+			Gas:       gasLimit,
+			To:        to,
+			Value:     value,
+			Data:      data,
+		})
+	}
+	return types.NewTx(&types.LegacyTx{
+		Nonce:    nonce,
+		GasPrice: fees.GasPrice,
+		Gas:      gasLimit,
+		To:       to,
+		Value:    value,
+		Data:     data,
+	})
+}
 
-		parts := strings.SplitN(line, "=", 2)
-		if len(parts) == 2 {
-			envVars[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
-		}
+// signTx 使用指定钱包签名交易
+func (m *Manager) signTx(index int, tx *types.Transaction) (*types.Transaction, error) {
+	// 获取私钥
+	privateKey := m.GetPrivateKeyByIndex(index)
+	if privateKey == nil {
+		return nil, fmt.Errorf("没有可用的私钥")
 	}
 
-	// 根据网络返回对应的RPC URL
-	switch network {
-	case "sepolia":
-		if url, exists := envVars["SEPOLIA_RPC_URL"]; exists {
-			return url
-		}
-	case "goerli":
-		if url, exists := envVars["GOERLI_RPC_URL"]; exists {
-			return url
-		}
-	case "mainnet":
-		if url, exists := envVars["MAINNET_RPC_URL"]; exists {
-			return url
-		}
-	case "bnb":
-		if url, exists := envVars["BNB_RPC_URL"]; exists {
-			return url
-		}
-	case "polygon":
-		if url, exists := envVars["POLYGON_RPC_URL"]; exists {
-			return url
-		}
+	signedTx, err := types.SignTx(tx, types.LatestSignerForChainID(m.GetChainID()), privateKey)
+	if err != nil {
+		return nil, fmt.Errorf("签名交易失败: %v", err)
 	}
+	return signedTx, nil
+}
 
-	return ""
+// RPC配置来源的优先级，数值越小越优先
+const (
+	RPCTierCustom  = iota // 命令行 --rpc 或批量配置文件 rpc_config
+	RPCTierEnv            // 环境变量
+	RPCTierGlobal         // 全局配置 rpc_config
+	RPCTierNetwork        // 网络定义 rpc_urls
+)
+
+// RPCSource RPC节点URL及其配置来源
+type RPCSource struct {
+	URL    string
+	Source string
+	Tier   int // 来源优先级，连接池优先使用最高优先级的节点
 }
 
-// loadEnvFile 加载环境变量文件
-func loadEnvFile(envFile string) {
-	// 检查文件是否存在
-	if _, err := os.Stat(envFile); os.IsNotExist(err) {
-		return
+// ListRPCSources 列出网络的所有RPC节点及来源，按优先级排序并去重
+// 优先级：自定义RPC > 环境变量 > 全局配置 > 网络定义；每处均可用逗号分隔多个URL
+// 读取环境变量来源失败（如加密密钥文件口令错误）时返回错误
+func ListRPCSources(envFile, network string, customRPCs map[string]string) ([]RPCSource, error) {
+	var sources []RPCSource
+	seen := make(map[string]bool)
+	add := func(value, source string, tier int) {
+		for _, url := range strings.Split(value, ",") {
+			url = strings.TrimSpace(url)
+			if url == "" || seen[url] {
+				continue
+			}
+			seen[url] = true
+			sources = append(sources, RPCSource{URL: url, Source: source, Tier: tier})
+		}
 	}
 
-	// 读取文件内容
-	content, err := ioutil.ReadFile(envFile)
+	// 1. 自定义RPC配置（命令行或批量配置文件）
+	if customRPCs != nil {
+		add(customRPCs[network], "自定义配置", RPCTierCustom)
+	}
+
+	// 2. 环境变量
+	envRPC, err := getRPCFromEnv(envFile, network)
 	if err != nil {
-		return
+		return nil, err
 	}
+	add(envRPC, "环境变量 "+rpcEnvKey(network), RPCTierEnv)
 
-	// 解析环境变量并设置到系统环境变量中
-	lines := strings.Split(string(content), "\n")
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if line == "" || strings.HasPrefix(line, "#") {
-			continue
-		}
+	// 3. 全局RPC配置文件
+	if globalRPC, err := loadGlobalRPCConfig(); err == nil && globalRPC != nil {
+		add(globalRPC[network], "全局配置 rpc_config", RPCTierGlobal)
+	}
 
-		parts := strings.SplitN(line, "=", 2)
-		if len(parts) == 2 {
-			key := strings.TrimSpace(parts[0])
-			value := strings.TrimSpace(parts[1])
-			// 只有当环境变量不存在时才设置
-			if os.Getenv(key) == "" {
-				os.Setenv(key, value)
-			}
-		}
+	// 4. 网络定义中的 rpc_urls
+	if networks, err := LoadNetworks(); err == nil {
+		add(strings.Join(networks[network].RPCURLs, ","), "网络定义 rpc_urls", RPCTierNetwork)
 	}
+
+	return sources, nil
+}
+
+// getRPCFromEnv 从环境变量及密钥来源读取RPC配置
+func getRPCFromEnv(envFile, network string) (string, error) {
+	// 确保.env中的配置已加载到环境变量
+	loadEnvFile(envFile)
+
+	// 根据网络确定对应的环境变量，如 sepolia -> SEPOLIA_RPC_URL
+	key := rpcEnvKey(network)
+
+	// RPC URL 可能包含API密钥，支持 KEY_FILE / KEY_CMD / 加密密钥文件
+	return secrets.Get(key)
+}
+
+// loadEnvFile 加载环境变量文件
+func loadEnvFile(envFile string) {
+	secrets.LoadEnvFile(envFile)
 }
 
 // loadGlobalRPCConfig 加载全局RPC配置
@@ -455,7 +631,7 @@ func expandEnvVariables(value string) string {
 	// 简单的环境变量替换，支持 ${VAR_NAME} 格式
 	if strings.HasPrefix(value, "${") && strings.HasSuffix(value, "}") {
 		envVar := strings.TrimSuffix(strings.TrimPrefix(value, "${"), "}")
-		if envValue := os.Getenv(envVar); envValue != "" {
+		if envValue, err := secrets.Get(envVar); err == nil && envValue != "" {
 			return envValue
 		}
 		// 如果系统环境变量中没有，尝试从.env文件读取
@@ -469,7 +645,7 @@ func expandEnvVariables(value string) string {
 // getEnvFromFile 从.env文件读取环境变量
 func getEnvFromFile(envVar string) string {
 	// 尝试从常见的.env文件读取
-	envFiles := []string{".env", "configs/.env", "configs/env.example"}
+	envFiles := []string{".env", "configs/.env"}
 
 	for _, envFile := range envFiles {
 		if _, err := os.Stat(envFile); os.IsNotExist(err) {
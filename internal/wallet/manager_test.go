@@ -0,0 +1,33 @@
+package wallet
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// TestNewKeyManagerPrefersKeyFile .env 中残留模板占位私钥时，PRIVATE_KEYS_FILE 指定的私钥仍然生效
+func TestNewKeyManagerPrefersKeyFile(t *testing.T) {
+	dir := t.TempDir()
+	keyFile := filepath.Join(dir, "keys.txt")
+	if err := os.WriteFile(keyFile, []byte(hexutil.Encode(crypto.Keccak256([]byte("cow")))+"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	envFile := filepath.Join(dir, ".env")
+	if err := os.WriteFile(envFile, []byte("PRIVATE_KEYS=your_private_key_1,your_private_key_2\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PRIVATE_KEYS", "")
+	t.Setenv("PRIVATE_KEYS_FILE", keyFile)
+
+	wm, err := NewKeyManager(envFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := wm.GetAddressByIndex(0).Hex(); got != eip712MailSigner {
+		t.Errorf("钱包地址为 %s，期望 %s", got, eip712MailSigner)
+	}
+}
@@ -0,0 +1,215 @@
+package wallet
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// Multicall3Address Multicall3 合约地址，在绝大多数EVM链上相同
+var Multicall3Address = common.HexToAddress("0xcA11bde05977b3631167028862bE2a173976CA11")
+
+// multicallChunkSize 单次 aggregate3 调用包含的子调用数，避免超出节点 eth_call 的Gas上限
+const multicallChunkSize = 500
+
+// multicall3ABIJSON Multicall3 中用到的方法
+const multicall3ABIJSON = `[
+	{"type":"function","name":"aggregate3","stateMutability":"payable","inputs":[{"name":"calls","type":"tuple[]","components":[{"name":"target","type":"address"},{"name":"allowFailure","type":"bool"},{"name":"callData","type":"bytes"}]}],"outputs":[{"name":"returnData","type":"tuple[]","components":[{"name":"success","type":"bool"},{"name":"returnData","type":"bytes"}]}]},
+	{"type":"function","name":"getEthBalance","stateMutability":"view","inputs":[{"name":"addr","type":"address"}],"outputs":[{"name":"balance","type":"uint256"}]}
+]`
+
+var multicall3ABI = mustParseABI(multicall3ABIJSON)
+
+// multicall3Call aggregate3 的单个子调用
+type multicall3Call struct {
+	Target       common.Address
+	AllowFailure bool
+	CallData     []byte
+}
+
+// multicall3Result aggregate3 的单个子调用结果
+type multicall3Result struct {
+	Success    bool
+	ReturnData []byte
+}
+
+// BalanceSnapshot 同一区块下多个地址的余额
+type BalanceSnapshot struct {
+	BlockNumber uint64
+	Token       *TokenInfo // 为空表示原生代币
+	Results     []BalanceResult
+	Multicall   bool // 是否通过 Multicall3 查询
+}
+
+// GetBalanceSnapshot 查询多个地址在同一区块的原生代币或ERC-20余额
+// 链上部署了 Multicall3 时合并为少量 eth_call，否则退回JSON-RPC批量请求；block 为空表示最新区块
+func (m *Manager) GetBalanceSnapshot(ctx context.Context, addresses []common.Address, token *TokenInfo, block *big.Int) (*BalanceSnapshot, error) {
+	// 固定区块号，保证所有地址的余额来自同一区块
+	if block == nil {
+		var number uint64
+		err := m.pool.Do(ctx, func(ctx context.Context, client *ethclient.Client) (err error) {
+			number, err = client.BlockNumber(ctx)
+			return err
+		})
+		if err != nil {
+			return nil, fmt.Errorf("获取最新区块失败: %v", err)
+		}
+		block = new(big.Int).SetUint64(number)
+	}
+
+	snapshot := &BalanceSnapshot{BlockNumber: block.Uint64(), Token: token}
+
+	if m.hasMulticall(ctx, block) {
+		results, err := m.multicallBalances(ctx, addresses, token, block)
+		if err == nil {
+			snapshot.Results = results
+			snapshot.Multicall = true
+			return snapshot, nil
+		}
+		if ctx.Err() != nil {
+			return nil, err
+		}
+		// Multicall3 调用失败（如超出节点Gas上限）时退回批量请求
+	}
+
+	results, err := m.batchBalances(ctx, addresses, token, block)
+	if err != nil {
+		return nil, err
+	}
+	snapshot.Results = results
+	return snapshot, nil
+}
+
+// hasMulticall 检查指定区块上是否部署了 Multicall3（历史区块上可能尚未部署）
+func (m *Manager) hasMulticall(ctx context.Context, block *big.Int) bool {
+	var code []byte
+	err := m.pool.Do(ctx, func(ctx context.Context, client *ethclient.Client) (err error) {
+		code, err = client.CodeAt(ctx, Multicall3Address, block)
+		return err
+	})
+	return err == nil && len(code) > 0
+}
+
+// multicallBalances 通过 Multicall3 aggregate3 批量查询余额
+func (m *Manager) multicallBalances(ctx context.Context, addresses []common.Address, token *TokenInfo, block *big.Int) ([]BalanceResult, error) {
+	calls := make([]multicall3Call, len(addresses))
+	for i, address := range addresses {
+		var err error
+		if token == nil {
+			calls[i].Target = Multicall3Address
+			calls[i].CallData, err = multicall3ABI.Pack("getEthBalance", address)
+		} else {
+			calls[i].Target = token.Address
+			calls[i].CallData, err = ERC20ABI.Pack("balanceOf", address)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("编码调用失败: %v", err)
+		}
+		calls[i].AllowFailure = true
+	}
+
+	results := make([]BalanceResult, 0, len(addresses))
+	for start := 0; start < len(calls); start += multicallChunkSize {
+		end := start + multicallChunkSize
+		if end > len(calls) {
+			end = len(calls)
+		}
+
+		returns, err := m.aggregate3(ctx, calls[start:end], block)
+		if err != nil {
+			return nil, err
+		}
+		if len(returns) != end-start {
+			return nil, fmt.Errorf("Multicall3 返回结果数量不匹配: 期望 %d，实际 %d", end-start, len(returns))
+		}
+
+		for i, ret := range returns {
+			result := BalanceResult{Address: addresses[start+i]}
+			if !ret.Success || len(ret.ReturnData) < 32 {
+				result.Err = fmt.Errorf("查询余额失败: 调用被回滚")
+			} else {
+				result.Balance = new(big.Int).SetBytes(ret.ReturnData[:32])
+			}
+			results = append(results, result)
+		}
+	}
+	return results, nil
+}
+
+// aggregate3 在指定区块执行 Multicall3 aggregate3
+func (m *Manager) aggregate3(ctx context.Context, calls []multicall3Call, block *big.Int) ([]multicall3Result, error) {
+	data, err := multicall3ABI.Pack("aggregate3", calls)
+	if err != nil {
+		return nil, fmt.Errorf("编码 aggregate3 失败: %v", err)
+	}
+
+	var output []byte
+	msg := ethereum.CallMsg{To: &Multicall3Address, Data: data}
+	err = m.pool.Do(ctx, func(ctx context.Context, client *ethclient.Client) (err error) {
+		output, err = client.CallContract(ctx, msg, block)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("调用 Multicall3 失败: %v", err)
+	}
+
+	var returns []multicall3Result
+	if err := multicall3ABI.UnpackIntoInterface(&returns, "aggregate3", output); err != nil {
+		return nil, fmt.Errorf("解析 Multicall3 返回值失败: %v", err)
+	}
+	return returns, nil
+}
+
+// batchBalances 通过JSON-RPC批量请求查询指定区块的余额
+func (m *Manager) batchBalances(ctx context.Context, addresses []common.Address, token *TokenInfo, block *big.Int) ([]BalanceResult, error) {
+	blockArg := hexutil.EncodeBig(block)
+	outputs := make([]hexutil.Bytes, len(addresses))
+	balances := make([]hexutil.Big, len(addresses))
+	elems := make([]rpc.BatchElem, len(addresses))
+	for i, address := range addresses {
+		if token == nil {
+			elems[i] = rpc.BatchElem{
+				Method: "eth_getBalance",
+				Args:   []interface{}{address, blockArg},
+				Result: &balances[i],
+			}
+			continue
+		}
+
+		data, err := ERC20ABI.Pack("balanceOf", address)
+		if err != nil {
+			return nil, fmt.Errorf("编码调用失败: %v", err)
+		}
+		elems[i] = rpc.BatchElem{
+			Method: "eth_call",
+			Args:   []interface{}{toCallArg(ethereum.CallMsg{To: &token.Address, Data: data}), blockArg},
+			Result: &outputs[i],
+		}
+	}
+
+	if err := m.pool.BatchCall(ctx, elems); err != nil {
+		return nil, fmt.Errorf("查询余额失败: %v", err)
+	}
+
+	results := make([]BalanceResult, len(addresses))
+	for i, address := range addresses {
+		results[i].Address = address
+		switch {
+		case elems[i].Error != nil:
+			results[i].Err = fmt.Errorf("查询余额失败: %v", elems[i].Error)
+		case token == nil:
+			results[i].Balance = balances[i].ToInt()
+		case len(outputs[i]) < 32:
+			results[i].Err = fmt.Errorf("查询余额失败: balanceOf 返回值无效")
+		default:
+			results[i].Balance = new(big.Int).SetBytes(outputs[i][:32])
+		}
+	}
+	return results, nil
+}
@@ -0,0 +1,337 @@
+package wallet
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"gopkg.in/yaml.v3"
+)
+
+// AutoNetwork 自动网络：根据RPC节点返回的链ID推导网络配置
+const AutoNetwork = "auto"
+
+// NormalizeNetworkName 规范化网络名称（去除首尾空白并转为小写），网络名称不区分大小写
+func NormalizeNetworkName(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+// chainIDTimeout 连接时查询链ID的超时时间
+const chainIDTimeout = 15 * time.Second
+
+// NetworkConfig 网络配置
+type NetworkConfig struct {
+	RPCURL          string
+	ChainID         *big.Int
+	Name            string
+	Explorer        string // 交易链接模板，{hash} 为交易哈希；无占位符时直接拼接
+	ExplorerAddress string // 地址链接模板，{address} 为地址；无占位符时直接拼接
+	Symbol          string
+	Decimals        int
+	RPCURLs         []string
+	EIP1559         bool
+	RPCRateLimit    float64        // 每个RPC节点每秒最多请求数，0 表示不限流
+	MinBalance      *big.Int       // 钱包最低余额告警阈值，为空表示不告警
+	GasLimitBuffer  int            // 估算Gas后增加的缓冲百分比
+	MaxFeeCap       *big.Int       // 每单位Gas最高费用上限（maxFeePerGas 或 gasPrice），为空表示不限制
+	DisperseAddress common.Address // 批量转账 disperse 模式使用的合约地址
+}
+
+// 默认网络配置（仅包含链ID和名称，RPC URL从配置文件读取）
+var defaultNetworkConfigs = map[string]NetworkConfig{
+	"sepolia": {
+		ChainID:         big.NewInt(11155111),
+		Name:            "Sepolia",
+		Explorer:        "https://sepolia.etherscan.io/tx/",
+		ExplorerAddress: "https://sepolia.etherscan.io/address/",
+		Symbol:          "ETH",
+		Decimals:        18,
+		EIP1559:         true,
+	},
+	"goerli": {
+		ChainID:         big.NewInt(5),
+		Name:            "Goerli",
+		Explorer:        "https://goerli.etherscan.io/tx/",
+		ExplorerAddress: "https://goerli.etherscan.io/address/",
+		Symbol:          "ETH",
+		Decimals:        18,
+		EIP1559:         true,
+	},
+	"mainnet": {
+		ChainID:         big.NewInt(1),
+		Name:            "Mainnet",
+		Explorer:        "https://etherscan.io/tx/",
+		ExplorerAddress: "https://etherscan.io/address/",
+		Symbol:          "ETH",
+		Decimals:        18,
+		EIP1559:         true,
+	},
+	"bnb": {
+		ChainID:         big.NewInt(56),
+		Name:            "BNB Smart Chain",
+		Explorer:        "https://bscscan.com/tx/",
+		ExplorerAddress: "https://bscscan.com/address/",
+		Symbol:          "BNB",
+		Decimals:        18,
+		EIP1559:         false,
+	},
+	"polygon": {
+		ChainID:         big.NewInt(137),
+		Name:            "Polygon",
+		Explorer:        "https://polygonscan.com/tx/",
+		ExplorerAddress: "https://polygonscan.com/address/",
+		Symbol:          "POL",
+		Decimals:        18,
+		EIP1559:         true,
+	},
+}
+
+// networkOverride 配置文件 networks 部分的单个网络定义，未填写的字段沿用内置配置
+type networkOverride struct {
+	Name               string   `yaml:"name"`
+	ChainID            int64    `yaml:"chain_id"`
+	ExplorerTxURL      string   `yaml:"explorer_tx_url"`
+	ExplorerAddressURL string   `yaml:"explorer_address_url"`
+	NativeSymbol       string   `yaml:"native_symbol"`
+	Decimals           int      `yaml:"decimals"`
+	RPCURLs            []string `yaml:"rpc_urls"`
+	EIP1559            *bool    `yaml:"eip1559"`
+	RPCRateLimit       float64  `yaml:"rpc_rps"`
+	MinBalance         string   `yaml:"min_balance"`
+	GasLimitBuffer     *int     `yaml:"gas_limit_buffer"`
+	MaxFeeGwei         string   `yaml:"max_fee_gwei"`
+	DisperseAddress    string   `yaml:"disperse_address"`
+}
+
+// LoadNetworks 加载所有网络配置（内置网络 + 配置文件 networks 部分）
+func LoadNetworks() (map[string]NetworkConfig, error) {
+	networks := make(map[string]NetworkConfig, len(defaultNetworkConfigs))
+	for name, cfg := range defaultNetworkConfigs {
+		cfg.GasLimitBuffer = DefaultGasLimitBuffer
+		cfg.DisperseAddress = DefaultDisperseAddress
+		networks[name] = cfg
+	}
+
+	overrides, err := loadNetworkOverrides()
+	if err != nil {
+		return nil, err
+	}
+
+	for name, override := range overrides {
+		name = NormalizeNetworkName(name)
+		cfg, builtin := networks[name]
+
+		if override.Name != "" {
+			cfg.Name = override.Name
+		}
+		if override.ChainID > 0 {
+			cfg.ChainID = big.NewInt(override.ChainID)
+		}
+		if override.ExplorerTxURL != "" {
+			cfg.Explorer = override.ExplorerTxURL
+		}
+		if override.ExplorerAddressURL != "" {
+			cfg.ExplorerAddress = override.ExplorerAddressURL
+		}
+		if override.NativeSymbol != "" {
+			cfg.Symbol = override.NativeSymbol
+		}
+		if override.Decimals > 0 {
+			cfg.Decimals = override.Decimals
+		}
+		if len(override.RPCURLs) > 0 {
+			cfg.RPCURLs = make([]string, 0, len(override.RPCURLs))
+			for _, url := range override.RPCURLs {
+				cfg.RPCURLs = append(cfg.RPCURLs, expandEnvVariables(url))
+			}
+		}
+		if override.EIP1559 != nil {
+			cfg.EIP1559 = *override.EIP1559
+		}
+		if override.RPCRateLimit > 0 {
+			cfg.RPCRateLimit = override.RPCRateLimit
+		}
+		if override.GasLimitBuffer != nil {
+			if *override.GasLimitBuffer < 0 {
+				return nil, fmt.Errorf("网络 %s 的 gas_limit_buffer 不能为负数", name)
+			}
+			cfg.GasLimitBuffer = *override.GasLimitBuffer
+		}
+		if override.MaxFeeGwei != "" && override.MaxFeeGwei != "0" {
+			maxFee, err := ParseUnits(override.MaxFeeGwei, 9)
+			if err != nil {
+				return nil, fmt.Errorf("网络 %s 的 max_fee_gwei 无效: %v", name, err)
+			}
+			cfg.MaxFeeCap = maxFee
+		}
+		if override.DisperseAddress != "" {
+			if !common.IsHexAddress(override.DisperseAddress) {
+				return nil, fmt.Errorf("网络 %s 的 disperse_address 无效: %s", name, override.DisperseAddress)
+			}
+			cfg.DisperseAddress = common.HexToAddress(override.DisperseAddress)
+		}
+
+		// 自定义网络的必填项和默认值
+		if !builtin {
+			if cfg.ChainID == nil {
+				return nil, fmt.Errorf("网络 %s 缺少 chain_id 配置", name)
+			}
+			if cfg.Name == "" {
+				cfg.Name = name
+			}
+			if cfg.Symbol == "" {
+				cfg.Symbol = "ETH"
+			}
+			if cfg.Decimals == 0 {
+				cfg.Decimals = 18
+			}
+			if override.GasLimitBuffer == nil {
+				cfg.GasLimitBuffer = DefaultGasLimitBuffer
+			}
+			if override.DisperseAddress == "" {
+				cfg.DisperseAddress = DefaultDisperseAddress
+			}
+		}
+
+		// 最低余额按原生代币精度解析，需在精度确定之后
+		if override.MinBalance != "" && override.MinBalance != "0" {
+			minBalance, err := ParseUnits(override.MinBalance, cfg.Decimals)
+			if err != nil {
+				return nil, fmt.Errorf("网络 %s 的 min_balance 无效: %v", name, err)
+			}
+			cfg.MinBalance = minBalance
+		}
+
+		networks[name] = cfg
+	}
+
+	return networks, nil
+}
+
+// GetNetwork 获取指定网络的配置
+func GetNetwork(network string) (NetworkConfig, error) {
+	network = NormalizeNetworkName(network)
+	networks, err := LoadNetworks()
+	if err != nil {
+		return NetworkConfig{}, err
+	}
+
+	cfg, exists := networks[network]
+	if !exists {
+		return NetworkConfig{}, fmt.Errorf("不支持的网络: %s (可用网络: %s)", network, strings.Join(sortedNetworkNames(networks), ", "))
+	}
+	return cfg, nil
+}
+
+// NetworkNames 获取所有可用网络名称
+func NetworkNames() ([]string, error) {
+	networks, err := LoadNetworks()
+	if err != nil {
+		return nil, err
+	}
+	return sortedNetworkNames(networks), nil
+}
+
+// sortedNetworkNames 按名称排序网络
+func sortedNetworkNames(networks map[string]NetworkConfig) []string {
+	names := make([]string, 0, len(networks))
+	for name := range networks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// networkByChainID 根据链ID匹配已配置的网络，未匹配时生成通用配置
+func networkByChainID(ctx context.Context, client *ethclient.Client, chainID *big.Int) (string, NetworkConfig, error) {
+	networks, err := LoadNetworks()
+	if err != nil {
+		return "", NetworkConfig{}, err
+	}
+
+	for _, name := range sortedNetworkNames(networks) {
+		if cfg := networks[name]; cfg.ChainID != nil && cfg.ChainID.Cmp(chainID) == 0 {
+			return name, cfg, nil
+		}
+	}
+
+	// 未知链：通过最新区块是否包含 baseFee 判断是否支持 EIP-1559
+	cfg := NetworkConfig{
+		ChainID:         chainID,
+		Name:            fmt.Sprintf("Chain %s", chainID.String()),
+		Symbol:          "ETH",
+		Decimals:        18,
+		GasLimitBuffer:  DefaultGasLimitBuffer,
+		DisperseAddress: DefaultDisperseAddress,
+	}
+	if header, err := client.HeaderByNumber(ctx, nil); err == nil {
+		cfg.EIP1559 = header.BaseFee != nil
+	}
+	return fmt.Sprintf("chain-%s", chainID.String()), cfg, nil
+}
+
+// IsMainnet 是否为以太坊主网
+func (n NetworkConfig) IsMainnet() bool {
+	return n.ChainID != nil && n.ChainID.Cmp(big.NewInt(1)) == 0
+}
+
+// TxURL 根据模板生成交易链接
+func (n NetworkConfig) TxURL(txHash string) string {
+	return fillURLTemplate(n.Explorer, "{hash}", txHash)
+}
+
+// AddressURL 根据模板生成地址链接
+func (n NetworkConfig) AddressURL(address string) string {
+	return fillURLTemplate(n.ExplorerAddress, "{address}", address)
+}
+
+// fillURLTemplate 填充链接模板
+func fillURLTemplate(template, placeholder, value string) string {
+	if template == "" {
+		return ""
+	}
+	if strings.Contains(template, placeholder) {
+		return strings.ReplaceAll(template, placeholder, value)
+	}
+	return template + value
+}
+
+// rpcEnvKey 网络对应的RPC环境变量名，如 sepolia -> SEPOLIA_RPC_URL
+func rpcEnvKey(network string) string {
+	key := strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return '_'
+	}, network)
+	return strings.ToUpper(key) + "_RPC_URL"
+}
+
+// loadNetworkOverrides 从全局配置文件读取 networks 部分
+func loadNetworkOverrides() (map[string]networkOverride, error) {
+	configFile := "configs/config.yaml"
+	if _, err := os.Stat(configFile); os.IsNotExist(err) {
+		return nil, nil
+	}
+
+	content, err := ioutil.ReadFile(configFile)
+	if err != nil {
+		return nil, fmt.Errorf("读取配置文件失败: %v", err)
+	}
+
+	var config struct {
+		Networks map[string]networkOverride `yaml:"networks"`
+	}
+	if err := yaml.Unmarshal(content, &config); err != nil {
+		return nil, fmt.Errorf("解析配置文件失败: %v", err)
+	}
+
+	return config.Networks, nil
+}
@@ -0,0 +1,20 @@
+package wallet
+
+import "testing"
+
+// TestGetNetworkIgnoresCase 网络名称不区分大小写，忽略首尾空白
+func TestGetNetworkIgnoresCase(t *testing.T) {
+	for _, name := range []string{"polygon", "Polygon", " POLYGON "} {
+		cfg, err := GetNetwork(name)
+		if err != nil {
+			t.Errorf("GetNetwork(%q): %v", name, err)
+			continue
+		}
+		if cfg.ChainID.Int64() != 137 {
+			t.Errorf("GetNetwork(%q) 链ID为 %s，期望 137", name, cfg.ChainID)
+		}
+	}
+	if _, err := GetNetwork("polygon-x"); err == nil {
+		t.Error("未知网络应报错")
+	}
+}
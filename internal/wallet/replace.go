@@ -0,0 +1,149 @@
+package wallet
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// replacementBumpPercent 替换交易的最低费用涨幅，节点要求至少上涨10%，这里留出余量
+const replacementBumpPercent = 112
+
+// cancelGasLimit 取消交易（向自己转账0）的Gas限制
+const cancelGasLimit = 21000
+
+// Replacement 已签名、待广播的替换交易
+type Replacement struct {
+	Original *types.Transaction
+	Tx       *types.Transaction
+	From     common.Address
+	Fees     *GasFees
+	Cancel   bool
+}
+
+// GetTransaction 按哈希查询交易，返回交易及是否仍在等待打包
+func (m *Manager) GetTransaction(ctx context.Context, hash common.Hash) (*types.Transaction, bool, error) {
+	var tx *types.Transaction
+	var pending bool
+	err := m.pool.Do(ctx, func(ctx context.Context, client *ethclient.Client) (err error) {
+		tx, pending, err = client.TransactionByHash(ctx, hash)
+		return err
+	})
+	if err == ethereum.NotFound {
+		return nil, false, fmt.Errorf("未找到交易 %s（可能已被替换、被节点丢弃或不在当前网络）", hash.Hex())
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("查询交易失败: %v", err)
+	}
+	return tx, pending, nil
+}
+
+// PrepareReplacement 为待打包的交易构建相同nonce、更高费用的替换交易并签名
+// cancel 为 true 时替换为向自己转账0，否则保持原交易内容（加速）
+func (m *Manager) PrepareReplacement(ctx context.Context, hash common.Hash, cancel bool, opts GasOptions) (*Replacement, error) {
+	tx, pending, err := m.GetTransaction(ctx, hash)
+	if err != nil {
+		return nil, err
+	}
+	if !pending {
+		return nil, fmt.Errorf("交易 %s 已打包，无需替换", hash.Hex())
+	}
+
+	from, err := types.Sender(types.LatestSignerForChainID(m.GetChainID()), tx)
+	if err != nil {
+		return nil, fmt.Errorf("解析交易发送方失败: %v", err)
+	}
+	index := -1
+	for i, address := range m.addresses {
+		if address == from {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		return nil, fmt.Errorf("交易发送方 %s 不是托管钱包，无法替换", from.Hex())
+	}
+
+	// nonce 已被已打包的交易使用时，原交易已打包或已被替换
+	var confirmedNonce uint64
+	err = m.pool.Do(ctx, func(ctx context.Context, client *ethclient.Client) (err error) {
+		confirmedNonce, err = client.NonceAt(ctx, from, nil)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("获取nonce失败: %v", err)
+	}
+	if confirmedNonce > tx.Nonce() {
+		return nil, fmt.Errorf("nonce %d 已被使用，交易已打包或已被替换", tx.Nonce())
+	}
+
+	fees, err := m.replacementFees(ctx, tx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var replacement *types.Transaction
+	if cancel {
+		replacement = newTx(&from, big.NewInt(0), nil, fees, cancelGasLimit, tx.Nonce(), m.GetChainID())
+	} else {
+		replacement = newTx(tx.To(), tx.Value(), tx.Data(), fees, tx.Gas(), tx.Nonce(), m.GetChainID())
+	}
+	signed, err := m.signTx(index, replacement)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Replacement{Original: tx, Tx: signed, From: from, Fees: fees, Cancel: cancel}, nil
+}
+
+// BroadcastTransaction 广播已签名的交易
+func (m *Manager) BroadcastTransaction(ctx context.Context, tx *types.Transaction) error {
+	if err := m.pool.Broadcast(ctx, tx); err != nil {
+		return fmt.Errorf("发送交易失败: %v", err)
+	}
+	return nil
+}
+
+// replacementFees 计算替换交易的费用：取当前策略费用与原费用上涨 replacementBumpPercent% 中的较高者
+func (m *Manager) replacementFees(ctx context.Context, tx *types.Transaction, opts GasOptions) (*GasFees, error) {
+	market, err := m.SuggestFees(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	fees := &GasFees{Strategy: market.Strategy, BaseFee: market.BaseFee}
+	if market.Dynamic() {
+		// 传统交易的 GasTipCap/GasFeeCap 均为 gasPrice，替换规则同样适用
+		fees.MaxPriorityFeePerGas = maxBig(market.MaxPriorityFeePerGas, bumpFee(tx.GasTipCap()))
+		fees.MaxFeePerGas = maxBig(market.MaxFeePerGas, bumpFee(tx.GasFeeCap()))
+		fees.MaxFeePerGas = maxBig(fees.MaxFeePerGas, fees.MaxPriorityFeePerGas)
+	} else {
+		fees.GasPrice = maxBig(market.GasPrice, bumpFee(tx.GasPrice()))
+	}
+
+	if limit := m.config.MaxFeeCap; limit != nil && fees.FeeCap().Cmp(limit) > 0 {
+		return nil, fmt.Errorf("替换交易需要 %s Gwei，超过网络 %s 的上限 %s Gwei (max_fee_gwei)",
+			FormatGwei(fees.FeeCap()), m.network, FormatGwei(limit))
+	}
+	return fees, nil
+}
+
+// bumpFee 按替换规则上调费用（向上取整）
+func bumpFee(fee *big.Int) *big.Int {
+	bumped := new(big.Int).Mul(fee, big.NewInt(replacementBumpPercent))
+	bumped.Add(bumped, big.NewInt(99))
+	return bumped.Div(bumped, big.NewInt(100))
+}
+
+// maxBig 返回较大值
+func maxBig(a, b *big.Int) *big.Int {
+	if a.Cmp(b) >= 0 {
+		return a
+	}
+	return b
+}
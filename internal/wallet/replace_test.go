@@ -0,0 +1,98 @@
+package wallet
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestBumpFee(t *testing.T) {
+	tests := []struct {
+		fee, want *big.Int
+	}{
+		{gwei(10), big.NewInt(11.2e9)},
+		{big.NewInt(1), big.NewInt(2)}, // 向上取整
+		{big.NewInt(100), big.NewInt(112)},
+		{big.NewInt(0), big.NewInt(0)},
+	}
+	for _, tt := range tests {
+		if got := bumpFee(tt.fee); got.Cmp(tt.want) != 0 {
+			t.Errorf("bumpFee(%s) = %s，期望 %s", tt.fee, got, tt.want)
+		}
+	}
+}
+
+// TestReplacementFees 替换费用取当前策略费用与原费用上涨12%中的较高者（模拟节点见 feeHistoryNode）
+func TestReplacementFees(t *testing.T) {
+	dynamicTx := func(tipGwei, maxGwei int64) *types.Transaction {
+		return types.NewTx(&types.DynamicFeeTx{GasTipCap: gwei(tipGwei), GasFeeCap: gwei(maxGwei)})
+	}
+	legacyTx := func(priceGwei int64) *types.Transaction {
+		return types.NewTx(&types.LegacyTx{GasPrice: gwei(priceGwei)})
+	}
+
+	tests := []struct {
+		name       string
+		tx         *types.Transaction
+		eip1559    bool
+		strategy   string
+		maxFeeGwei int64
+		wantMax    *big.Int // maxFeePerGas 或 gasPrice
+		wantTip    *big.Int // 传统交易为空
+		wantErr    bool
+	}{
+		{
+			name: "当前费用高于原费用×1.12", tx: dynamicTx(2, 100), eip1559: true, strategy: GasStandard,
+			wantMax: gwei(153), wantTip: gwei(3),
+		},
+		{
+			name: "当前费用低于原费用×1.12", tx: dynamicTx(4, 150), eip1559: true, strategy: GasStandard,
+			wantMax: gwei(168), wantTip: big.NewInt(4.48e9),
+		},
+		{
+			name: "maxFee 与小费分别取较高者", tx: dynamicTx(2, 100), eip1559: true, strategy: GasSlow,
+			wantMax: gwei(127), wantTip: big.NewInt(2.24e9),
+		},
+		{
+			// 传统交易的小费上限即 gasPrice
+			name: "替换传统交易", tx: legacyTx(5), eip1559: true, strategy: GasSlow,
+			wantMax: gwei(127), wantTip: big.NewInt(5.6e9),
+		},
+		{
+			name: "传统网络当前价格较低", tx: legacyTx(10), strategy: GasStandard, wantMax: big.NewInt(11.2e9),
+		},
+		{
+			name: "传统网络当前价格较高", tx: legacyTx(10), strategy: GasFast, wantMax: big.NewInt(12.5e9),
+		},
+		{
+			name: "上涨后超过上限", tx: dynamicTx(4, 150), eip1559: true, strategy: GasStandard, maxFeeGwei: 160,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		wm := newFeeTestManager(t, feeHistoryNode(), tt.eip1559, tt.maxFeeGwei)
+		fees, err := wm.replacementFees(context.Background(), tt.tx, GasOptions{Strategy: tt.strategy})
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("%s: 期望报错，得到 %s Gwei", tt.name, FormatGwei(fees.FeeCap()))
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if fees.FeeCap().Cmp(tt.wantMax) != 0 {
+			t.Errorf("%s: 费用上限 %s Gwei，期望 %s", tt.name, FormatGwei(fees.FeeCap()), FormatGwei(tt.wantMax))
+		}
+		if tt.wantTip == nil {
+			if fees.Dynamic() {
+				t.Errorf("%s: 传统网络不应使用 EIP-1559 费用", tt.name)
+			}
+		} else if fees.MaxPriorityFeePerGas == nil || fees.MaxPriorityFeePerGas.Cmp(tt.wantTip) != 0 {
+			t.Errorf("%s: 优先费 %v，期望 %s Gwei", tt.name, fees.MaxPriorityFeePerGas, FormatGwei(tt.wantTip))
+		}
+	}
+}
@@ -0,0 +1,552 @@
+package wallet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// RPC连接池参数
+const (
+	healthCheckInterval = 30 * time.Second
+	healthCheckTimeout  = 5 * time.Second
+	maxRPCAttempts      = 3
+	retryBackoff        = 500 * time.Millisecond
+	broadcastEndpoints  = 3
+	defaultCallTimeout  = 30 * time.Second
+	maxBatchSize        = 100             // 单个JSON-RPC批量请求的最大调用数，多数服务商限制在100左右
+	lagPenalty          = time.Second     // 每落后一个区块的评分惩罚
+	errorRatePenalty    = 5 * time.Second // 错误率为100%时的评分惩罚
+	unhealthyPenalty    = time.Hour       // 健康检查失败的评分惩罚
+)
+
+// rpcEndpoint 单个RPC节点及其健康状态
+type rpcEndpoint struct {
+	url    string
+	tier   int // 配置来源优先级，见 RPCSource.Tier
+	client *ethclient.Client
+	rpc    *rpc.Client
+
+	limiter *rateLimiter
+
+	mu        sync.Mutex
+	healthy   bool
+	verified  bool // 链ID已校验通过；校验前或校验失败的节点不参与路由
+	latency   time.Duration
+	head      uint64
+	lag       uint64
+	requests  int
+	failures  int
+	lastError error
+}
+
+// EndpointStatus RPC节点健康状态快照
+type EndpointStatus struct {
+	URL       string
+	Healthy   bool
+	Verified  bool
+	Latency   time.Duration
+	Head      uint64
+	Lag       uint64
+	Requests  int
+	Failures  int
+	LastError error
+}
+
+// RPCPool 多RPC节点连接池：健康检查、按来源优先级和评分路由请求、瞬时错误重试、多节点广播交易
+// 请求优先发往最高优先级来源中健康的节点，只有这些节点失败时才使用低优先级来源
+type RPCPool struct {
+	endpoints   []*rpcEndpoint
+	callTimeout time.Duration
+
+	mu        sync.Mutex
+	lastCheck time.Time
+	checking  bool     // 正在进行健康检查，避免并发请求同时触发
+	chainID   *big.Int // VerifyChainID 确认的链ID，为空表示尚未校验
+}
+
+// NewRPCPool 连接所有RPC节点并进行首次健康检查
+func NewRPCPool(ctx context.Context, sources []RPCSource) (*RPCPool, error) {
+	if len(sources) == 0 {
+		return nil, fmt.Errorf("没有可用的RPC节点")
+	}
+
+	pool := &RPCPool{callTimeout: defaultCallTimeout}
+	var dialErrors []string
+	for _, source := range sources {
+		rpcClient, err := rpc.DialContext(ctx, source.URL)
+		if err != nil {
+			dialErrors = append(dialErrors, fmt.Sprintf("%s: %v", source.URL, err))
+			continue
+		}
+		pool.endpoints = append(pool.endpoints, &rpcEndpoint{
+			url:     source.URL,
+			tier:    source.Tier,
+			client:  ethclient.NewClient(rpcClient),
+			rpc:     rpcClient,
+			healthy: true,
+		})
+	}
+
+	if len(pool.endpoints) == 0 {
+		return nil, fmt.Errorf("连接网络失败: %s", strings.Join(dialErrors, "; "))
+	}
+
+	pool.CheckHealth(ctx)
+	return pool, nil
+}
+
+// CheckHealth 并发检查所有节点的延迟和最新区块，计算区块落后数
+// 链ID未校验通过的节点同时重新查询链ID，与连接池一致后恢复路由
+func (p *RPCPool) CheckHealth(ctx context.Context) {
+	p.mu.Lock()
+	chainID := p.chainID
+	p.mu.Unlock()
+
+	var wg sync.WaitGroup
+	for _, ep := range p.endpoints {
+		wg.Add(1)
+		go func(ep *rpcEndpoint) {
+			defer wg.Done()
+
+			checkCtx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+			defer cancel()
+			if err := ep.limiter.Wait(checkCtx, 1); err != nil {
+				return
+			}
+
+			start := time.Now()
+			head, err := ep.client.BlockNumber(checkCtx)
+			latency := time.Since(start)
+
+			ep.mu.Lock()
+			defer ep.mu.Unlock()
+			ep.requests++
+			if err != nil {
+				ep.healthy = false
+				ep.failures++
+				ep.lastError = err
+				return
+			}
+			ep.healthy = true
+			ep.latency = latency
+			ep.head = head
+			if chainID != nil && !ep.verified {
+				ep.verifyChainID(checkCtx, chainID)
+			}
+		}(ep)
+	}
+	wg.Wait()
+
+	// 以所有节点中最高的区块为基准计算落后数
+	var maxHead uint64
+	for _, ep := range p.endpoints {
+		ep.mu.Lock()
+		if ep.healthy && ep.head > maxHead {
+			maxHead = ep.head
+		}
+		ep.mu.Unlock()
+	}
+	for _, ep := range p.endpoints {
+		ep.mu.Lock()
+		if ep.healthy {
+			ep.lag = maxHead - ep.head
+		}
+		ep.mu.Unlock()
+	}
+
+	p.mu.Lock()
+	p.lastCheck = time.Now()
+	p.checking = false
+	p.mu.Unlock()
+}
+
+// verifyChainID 查询节点链ID并与期望值比对，调用方持有 ep.mu
+func (ep *rpcEndpoint) verifyChainID(ctx context.Context, expected *big.Int) {
+	id, err := ep.client.ChainID(ctx)
+	ep.requests++
+	switch {
+	case err != nil:
+		ep.failures++
+		ep.lastError = err
+	case id.Cmp(expected) != 0:
+		ep.lastError = fmt.Errorf("链ID不一致: 期望 %s，节点返回 %s", expected.String(), id.String())
+	default:
+		ep.verified = true
+	}
+}
+
+// score 节点评分，越低越好
+func (ep *rpcEndpoint) score() time.Duration {
+	ep.mu.Lock()
+	defer ep.mu.Unlock()
+
+	score := ep.latency + time.Duration(ep.lag)*lagPenalty
+	if ep.requests > 0 {
+		score += time.Duration(float64(errorRatePenalty) * float64(ep.failures) / float64(ep.requests))
+	}
+	if !ep.healthy {
+		score += unhealthyPenalty
+	}
+	return score
+}
+
+// record 记录一次请求结果
+func (ep *rpcEndpoint) record(err error) {
+	ep.mu.Lock()
+	defer ep.mu.Unlock()
+	ep.requests++
+	if err != nil && isTransientError(err) {
+		ep.failures++
+		ep.lastError = err
+		ep.healthy = false
+	}
+}
+
+// ranked 按路由顺序排列的节点列表：健康节点在前，其次按来源优先级，同一优先级内按评分
+// 必要时先刷新健康状态（同一时间只有一个请求执行检查，其余使用现有评分）
+// 完成链ID校验后，只返回链ID已校验通过的节点
+func (p *RPCPool) ranked(ctx context.Context) []*rpcEndpoint {
+	p.mu.Lock()
+	check := !p.checking && len(p.endpoints) > 1 && time.Since(p.lastCheck) > healthCheckInterval
+	if check {
+		p.checking = true
+	}
+	verifying := p.chainID != nil
+	p.mu.Unlock()
+	if check {
+		p.CheckHealth(ctx)
+	}
+
+	endpoints := make([]*rpcEndpoint, 0, len(p.endpoints))
+	for _, ep := range p.endpoints {
+		ep.mu.Lock()
+		usable := !verifying || ep.verified
+		ep.mu.Unlock()
+		if usable {
+			endpoints = append(endpoints, ep)
+		}
+	}
+	sort.SliceStable(endpoints, func(i, j int) bool {
+		hi, hj := endpoints[i].isHealthy(), endpoints[j].isHealthy()
+		if hi != hj {
+			return hi
+		}
+		if endpoints[i].tier != endpoints[j].tier {
+			return endpoints[i].tier < endpoints[j].tier
+		}
+		return endpoints[i].score() < endpoints[j].score()
+	})
+	return endpoints
+}
+
+// isHealthy 节点最近一次检查或请求是否正常
+func (ep *rpcEndpoint) isHealthy() bool {
+	ep.mu.Lock()
+	defer ep.mu.Unlock()
+	return ep.healthy
+}
+
+// SetCallTimeout 设置单次RPC调用的超时时间，0 表示不限制
+func (p *RPCPool) SetCallTimeout(timeout time.Duration) {
+	p.callTimeout = timeout
+}
+
+// callContext 为单次调用附加超时
+func (p *RPCPool) callContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if p.callTimeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, p.callTimeout)
+}
+
+// SetRateLimit 设置每个节点每秒最多发出的请求数，批量请求按其中的调用数计算；0 表示不限流
+func (p *RPCPool) SetRateLimit(rps float64) {
+	for _, ep := range p.endpoints {
+		ep.limiter = newRateLimiter(rps)
+	}
+}
+
+// Do 在最健康的节点上执行请求，遇到瞬时错误时重试并切换节点，每次调用单独计算超时
+func (p *RPCPool) Do(ctx context.Context, fn func(ctx context.Context, client *ethclient.Client) error) error {
+	return p.do(ctx, 1, fn)
+}
+
+// BatchCall 以JSON-RPC批量请求执行多个调用，超过 maxBatchSize 时分批发送
+// 单个调用的错误记录在对应元素的 Error 中；节点不支持批量请求时逐个调用
+func (p *RPCPool) BatchCall(ctx context.Context, elems []rpc.BatchElem) error {
+	for start := 0; start < len(elems); start += maxBatchSize {
+		end := start + maxBatchSize
+		if end > len(elems) {
+			end = len(elems)
+		}
+		chunk := elems[start:end]
+
+		err := p.do(ctx, len(chunk), func(ctx context.Context, client *ethclient.Client) error {
+			return client.Client().BatchCallContext(ctx, chunk)
+		})
+		if err == nil {
+			continue
+		}
+		if ctx.Err() != nil || isTransientError(err) {
+			return err
+		}
+
+		for i := range chunk {
+			elem := &chunk[i]
+			elem.Error = p.Do(ctx, func(ctx context.Context, client *ethclient.Client) error {
+				return client.Client().CallContext(ctx, elem.Result, elem.Method, elem.Args...)
+			})
+		}
+	}
+	return nil
+}
+
+// do 执行请求，cost 为本次请求占用的限流令牌数
+func (p *RPCPool) do(ctx context.Context, cost int, fn func(ctx context.Context, client *ethclient.Client) error) error {
+	var lastErr error
+	for attempt := 0; attempt < maxRPCAttempts; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(time.Duration(attempt) * retryBackoff):
+			}
+		}
+
+		for _, ep := range p.ranked(ctx) {
+			if err := ep.limiter.Wait(ctx, cost); err != nil {
+				return err
+			}
+			callCtx, cancel := p.callContext(ctx)
+			err := fn(callCtx, ep.client)
+			cancel()
+
+			ep.record(err)
+			if err == nil {
+				return nil
+			}
+			lastErr = err
+			// 调用方已取消，或非瞬时错误（如余额不足、交易回滚）换节点也无济于事
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			if !isTransientError(err) {
+				return err
+			}
+		}
+	}
+	return lastErr
+}
+
+// Broadcast 将已签名交易同时广播到最高优先级来源的节点（最多 broadcastEndpoints 个），任一节点接受即视为成功
+// 不会发往低优先级来源，避免通过 --rpc 指定的私有或防MEV节点发送的交易泄露到公共节点
+func (p *RPCPool) Broadcast(ctx context.Context, tx *types.Transaction) error {
+	endpoints := p.broadcastTargets(p.ranked(ctx))
+	if len(endpoints) == 0 {
+		return fmt.Errorf("没有可用于广播交易的RPC节点（最高优先级来源的节点均未通过链ID校验）")
+	}
+
+	errs := make([]error, len(endpoints))
+	var wg sync.WaitGroup
+	for i, ep := range endpoints {
+		wg.Add(1)
+		go func(i int, ep *rpcEndpoint) {
+			defer wg.Done()
+			callCtx, cancel := p.callContext(ctx)
+			defer cancel()
+			if err := ep.limiter.Wait(callCtx, 1); err != nil {
+				errs[i] = err
+				return
+			}
+			err := ep.client.SendTransaction(callCtx, tx)
+			// 其他节点已转发过同一笔交易
+			if err != nil && isKnownTransactionError(err) {
+				err = nil
+			}
+			ep.record(err)
+			errs[i] = err
+		}(i, ep)
+	}
+	wg.Wait()
+
+	var firstErr error
+	for _, err := range errs {
+		if err == nil {
+			return nil
+		}
+		if firstErr == nil || (isTransientError(firstErr) && !isTransientError(err)) {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// broadcastTargets 从路由顺序的节点中选出属于连接池最高优先级来源的节点
+// 最高优先级按配置计算，该来源的节点都不可用时返回空列表，而不是退回低优先级来源
+func (p *RPCPool) broadcastTargets(ranked []*rpcEndpoint) []*rpcEndpoint {
+	top := p.endpoints[0].tier
+	for _, ep := range p.endpoints {
+		if ep.tier < top {
+			top = ep.tier
+		}
+	}
+	var targets []*rpcEndpoint
+	for _, ep := range ranked {
+		if ep.tier == top && len(targets) < broadcastEndpoints {
+			targets = append(targets, ep)
+		}
+	}
+	return targets
+}
+
+// SubscribeNewHead 通过评分最好的WebSocket节点订阅新区块
+func (p *RPCPool) SubscribeNewHead(ctx context.Context, ch chan<- *types.Header) (ethereum.Subscription, error) {
+	for _, ep := range p.ranked(ctx) {
+		if strings.HasPrefix(ep.url, "ws://") || strings.HasPrefix(ep.url, "wss://") {
+			return ep.client.SubscribeNewHead(ctx, ch)
+		}
+	}
+	return nil, fmt.Errorf("没有WebSocket节点，无法订阅新区块")
+}
+
+// Best 获取当前评分最好的节点客户端
+func (p *RPCPool) Best() *ethclient.Client {
+	return p.ranked(context.Background())[0].client
+}
+
+// URLs 获取所有节点URL
+func (p *RPCPool) URLs() []string {
+	urls := make([]string, len(p.endpoints))
+	for i, ep := range p.endpoints {
+		urls[i] = ep.url
+	}
+	return urls
+}
+
+// Status 获取所有节点的健康状态
+func (p *RPCPool) Status() []EndpointStatus {
+	statuses := make([]EndpointStatus, len(p.endpoints))
+	for i, ep := range p.endpoints {
+		ep.mu.Lock()
+		statuses[i] = EndpointStatus{
+			URL:       ep.url,
+			Healthy:   ep.healthy,
+			Verified:  ep.verified,
+			Latency:   ep.latency,
+			Head:      ep.head,
+			Lag:       ep.lag,
+			Requests:  ep.requests,
+			Failures:  ep.failures,
+			LastError: ep.lastError,
+		}
+		ep.mu.Unlock()
+	}
+	return statuses
+}
+
+// VerifyChainID 校验所有节点的链ID一致，返回该链ID
+// 查询失败的节点标记为未校验，不参与路由和广播，之后的健康检查确认链ID一致后恢复
+func (p *RPCPool) VerifyChainID(ctx context.Context) (*big.Int, error) {
+	var chainID *big.Int
+	var chainURL string
+	var verified []*rpcEndpoint
+	for _, ep := range p.endpoints {
+		id, err := ep.client.ChainID(ctx)
+		if err != nil {
+			ep.record(err)
+			continue
+		}
+		if chainID == nil {
+			chainID, chainURL = id, ep.url
+		} else if chainID.Cmp(id) != 0 {
+			return nil, fmt.Errorf("RPC节点链ID不一致: %s 返回 %s，%s 返回 %s",
+				chainURL, chainID.String(), ep.url, id.String())
+		}
+		verified = append(verified, ep)
+	}
+	if chainID == nil {
+		return nil, fmt.Errorf("查询链ID失败: 所有RPC节点均不可用 (%s)", strings.Join(p.URLs(), ", "))
+	}
+
+	for _, ep := range verified {
+		ep.mu.Lock()
+		ep.verified = true
+		ep.mu.Unlock()
+	}
+	p.mu.Lock()
+	p.chainID = chainID
+	p.mu.Unlock()
+	return chainID, nil
+}
+
+// Close 关闭所有连接
+func (p *RPCPool) Close() {
+	for _, ep := range p.endpoints {
+		ep.client.Close()
+	}
+}
+
+// JSON-RPC 限流错误码：-32005 为 EIP-1474 的 limit exceeded，部分服务商直接使用 429
+const (
+	rpcLimitExceededCode = -32005
+	rpcTooManyRequests   = 429
+)
+
+// isTransientError 判断是否为可重试的瞬时错误（网络故障、超时、连接中断、限流、服务端错误）
+// 只按错误类型和错误码判断，不匹配错误信息，避免把回滚原因或返回数据中的文字误判为瞬时错误
+func isTransientError(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+
+	var httpErr rpc.HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.StatusCode == http.StatusTooManyRequests || httpErr.StatusCode >= http.StatusInternalServerError
+	}
+
+	var rpcErr rpc.Error
+	if errors.As(err, &rpcErr) {
+		code := rpcErr.ErrorCode()
+		return code == rpcLimitExceededCode || code == rpcTooManyRequests
+	}
+	return false
+}
+
+// IsTransientError 判断是否为可重试的瞬时错误，供调用方决定是否稍后重试
+func IsTransientError(err error) bool {
+	return isTransientError(err)
+}
+
+// isJSONRPCError 判断是否为节点返回的JSON-RPC错误（请求已被节点处理）
+func isJSONRPCError(err error) bool {
+	var rpcErr rpc.Error
+	return errors.As(err, &rpcErr)
+}
+
+// isKnownTransactionError 判断是否为节点已收到该交易的错误
+func isKnownTransactionError(err error) bool {
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "already known") || strings.Contains(msg, "known transaction")
+}
@@ -0,0 +1,119 @@
+package wallet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// jsonRPCError 模拟节点返回的JSON-RPC错误
+type jsonRPCError struct {
+	code int
+	msg  string
+}
+
+func (e jsonRPCError) Error() string  { return e.msg }
+func (e jsonRPCError) ErrorCode() int { return e.code }
+
+func TestIsTransientError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"超时", context.DeadlineExceeded, true},
+		{"包装的超时", fmt.Errorf("查询失败: %w", context.DeadlineExceeded), true},
+		{"连接中断", io.ErrUnexpectedEOF, true},
+		{"EOF", io.EOF, true},
+		{"网络错误", &net.OpError{Op: "dial", Err: errors.New("connection refused")}, true},
+		{"HTTP 429", rpc.HTTPError{StatusCode: 429, Status: "429 Too Many Requests"}, true},
+		{"HTTP 502", rpc.HTTPError{StatusCode: 502, Status: "502 Bad Gateway"}, true},
+		{"HTTP 400", rpc.HTTPError{StatusCode: 400, Status: "400 Bad Request"}, false},
+		{"limit exceeded", jsonRPCError{-32005, "limit exceeded"}, true},
+		{"JSON-RPC 429", jsonRPCError{429, "too many requests"}, true},
+		{"回滚原因含429", jsonRPCError{3, "execution reverted: error 429"}, false},
+		{"回滚原因含gateway", jsonRPCError{3, "execution reverted: gateway paused"}, false},
+		{"余额不足", jsonRPCError{-32000, "insufficient funds for gas * price + value"}, false},
+		{"普通错误含eof", errors.New("unexpected eof in revert data"), false},
+		{"调用方取消", context.Canceled, false},
+	}
+	for _, tt := range tests {
+		if got := isTransientError(tt.err); got != tt.want {
+			t.Errorf("%s: isTransientError(%v) = %v，期望 %v", tt.name, tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestRankedExcludesUnverified(t *testing.T) {
+	verified := &rpcEndpoint{url: "http://a", healthy: true, verified: true, latency: time.Second}
+	unverified := &rpcEndpoint{url: "http://b", healthy: true, latency: time.Millisecond}
+	pool := &RPCPool{endpoints: []*rpcEndpoint{verified, unverified}, lastCheck: time.Now()}
+
+	// 尚未校验链ID时所有节点都参与路由
+	if got := pool.ranked(context.Background()); len(got) != 2 || got[0] != unverified {
+		t.Fatalf("校验前应返回全部节点并按评分排序，得到 %d 个", len(got))
+	}
+
+	pool.chainID = big.NewInt(1)
+	got := pool.ranked(context.Background())
+	if len(got) != 1 || got[0] != verified {
+		t.Fatalf("校验后应只返回已校验节点，得到 %d 个", len(got))
+	}
+}
+
+func endpointURLs(endpoints []*rpcEndpoint) string {
+	urls := make([]string, len(endpoints))
+	for i, ep := range endpoints {
+		urls[i] = ep.url
+	}
+	return strings.Join(urls, ",")
+}
+
+// TestRankedPrefersTier 健康节点中优先使用高优先级来源，同一来源按评分；高优先级节点不健康时才退回低优先级
+func TestRankedPrefersTier(t *testing.T) {
+	custom := &rpcEndpoint{url: "custom", tier: RPCTierCustom, healthy: true, latency: 300 * time.Millisecond}
+	envFast := &rpcEndpoint{url: "env-fast", tier: RPCTierEnv, healthy: true, latency: 10 * time.Millisecond}
+	envSlow := &rpcEndpoint{url: "env-slow", tier: RPCTierEnv, healthy: true, latency: 50 * time.Millisecond}
+	public := &rpcEndpoint{url: "public", tier: RPCTierNetwork, healthy: true, latency: time.Millisecond}
+	pool := &RPCPool{endpoints: []*rpcEndpoint{public, envSlow, custom, envFast}, lastCheck: time.Now()}
+
+	if got := endpointURLs(pool.ranked(context.Background())); got != "custom,env-fast,env-slow,public" {
+		t.Errorf("全部健康: %s", got)
+	}
+	if got := endpointURLs(pool.broadcastTargets(pool.ranked(context.Background()))); got != "custom" {
+		t.Errorf("全部健康时广播: %s", got)
+	}
+
+	custom.healthy = false
+	if got := endpointURLs(pool.ranked(context.Background())); got != "env-fast,env-slow,public,custom" {
+		t.Errorf("自定义节点不健康: %s", got)
+	}
+	// 广播不会扩散到 --rpc 以外的节点
+	if got := endpointURLs(pool.broadcastTargets(pool.ranked(context.Background()))); got != "custom" {
+		t.Errorf("自定义节点不健康时广播: %s", got)
+	}
+
+	// 自定义节点未通过链ID校验时不广播
+	pool.chainID = big.NewInt(1)
+	for _, ep := range []*rpcEndpoint{envFast, envSlow, public} {
+		ep.verified = true
+	}
+	if got := pool.broadcastTargets(pool.ranked(context.Background())); len(got) != 0 {
+		t.Errorf("自定义节点未校验时广播: %s", endpointURLs(got))
+	}
+
+	// 没有自定义节点时广播到环境变量来源的节点，不包括公共节点
+	pool = &RPCPool{endpoints: []*rpcEndpoint{public, envSlow, envFast}, lastCheck: time.Now()}
+	if got := endpointURLs(pool.broadcastTargets(pool.ranked(context.Background()))); got != "env-fast,env-slow" {
+		t.Errorf("环境变量来源广播: %s", got)
+	}
+}
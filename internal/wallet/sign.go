@@ -0,0 +1,109 @@
+package wallet
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+)
+
+// SignMessage 使用指定钱包对消息进行 EIP-191 (personal_sign) 签名
+func (m *Manager) SignMessage(index int, message []byte) ([]byte, error) {
+	privateKey := m.GetPrivateKeyByIndex(index)
+	if privateKey == nil {
+		return nil, fmt.Errorf("没有可用的私钥")
+	}
+
+	signature, err := crypto.Sign(accounts.TextHash(message), privateKey)
+	if err != nil {
+		return nil, fmt.Errorf("签名消息失败: %v", err)
+	}
+
+	// personal_sign 约定 V 值为 27/28
+	signature[crypto.RecoveryIDOffset] += 27
+	return signature, nil
+}
+
+// SignTypedData 使用指定钱包对 EIP-712 结构化数据进行签名
+func (m *Manager) SignTypedData(index int, typedData apitypes.TypedData) ([]byte, error) {
+	privateKey := m.GetPrivateKeyByIndex(index)
+	if privateKey == nil {
+		return nil, fmt.Errorf("没有可用的私钥")
+	}
+
+	hash, _, err := apitypes.TypedDataAndHash(typedData)
+	if err != nil {
+		return nil, fmt.Errorf("计算结构化数据哈希失败: %v", err)
+	}
+
+	signature, err := crypto.Sign(hash, privateKey)
+	if err != nil {
+		return nil, fmt.Errorf("签名结构化数据失败: %v", err)
+	}
+
+	signature[crypto.RecoveryIDOffset] += 27
+	return signature, nil
+}
+
+// ParseTypedData 解析 EIP-712 结构化数据JSON
+func ParseTypedData(data []byte) (apitypes.TypedData, error) {
+	var typedData apitypes.TypedData
+	if err := json.Unmarshal(data, &typedData); err != nil {
+		return typedData, fmt.Errorf("解析结构化数据失败: %v", err)
+	}
+	if typedData.PrimaryType == "" {
+		return typedData, fmt.Errorf("结构化数据缺少 primaryType 字段")
+	}
+	return typedData, nil
+}
+
+// RecoverMessageSigner 从 EIP-191 签名中恢复签名者地址
+func RecoverMessageSigner(message, signature []byte) (common.Address, error) {
+	return recoverSigner(accounts.TextHash(message), signature)
+}
+
+// RecoverTypedDataSigner 从 EIP-712 签名中恢复签名者地址
+func RecoverTypedDataSigner(typedData apitypes.TypedData, signature []byte) (common.Address, error) {
+	hash, _, err := apitypes.TypedDataAndHash(typedData)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("计算结构化数据哈希失败: %v", err)
+	}
+	return recoverSigner(hash, signature)
+}
+
+// ParseSignature 解析十六进制签名
+func ParseSignature(signatureStr string) ([]byte, error) {
+	signature, err := hexutil.Decode(strings.TrimSpace(signatureStr))
+	if err != nil {
+		return nil, fmt.Errorf("无效的签名格式: %v", err)
+	}
+	if len(signature) != crypto.SignatureLength {
+		return nil, fmt.Errorf("签名长度错误: 需要 %d 字节，实际 %d 字节", crypto.SignatureLength, len(signature))
+	}
+	return signature, nil
+}
+
+// recoverSigner 根据哈希和签名恢复地址
+func recoverSigner(hash, signature []byte) (common.Address, error) {
+	if len(signature) != crypto.SignatureLength {
+		return common.Address{}, fmt.Errorf("签名长度错误: %d", len(signature))
+	}
+
+	// 复制一份，避免修改调用方数据；兼容 V 值为 27/28 和 0/1 两种格式
+	sig := make([]byte, len(signature))
+	copy(sig, signature)
+	if sig[crypto.RecoveryIDOffset] >= 27 {
+		sig[crypto.RecoveryIDOffset] -= 27
+	}
+
+	publicKey, err := crypto.SigToPub(hash, sig)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("恢复签名者失败: %v", err)
+	}
+	return crypto.PubkeyToAddress(*publicKey), nil
+}
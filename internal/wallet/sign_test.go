@@ -0,0 +1,154 @@
+package wallet
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+)
+
+// EIP-712 规范中的 Mail 示例，签名私钥为 keccak256("cow")
+const eip712MailExample = `{
+  "types": {
+    "EIP712Domain": [
+      {"name": "name", "type": "string"},
+      {"name": "version", "type": "string"},
+      {"name": "chainId", "type": "uint256"},
+      {"name": "verifyingContract", "type": "address"}
+    ],
+    "Person": [
+      {"name": "name", "type": "string"},
+      {"name": "wallet", "type": "address"}
+    ],
+    "Mail": [
+      {"name": "from", "type": "Person"},
+      {"name": "to", "type": "Person"},
+      {"name": "contents", "type": "string"}
+    ]
+  },
+  "primaryType": "Mail",
+  "domain": {
+    "name": "Ether Mail",
+    "version": "1",
+    "chainId": 1,
+    "verifyingContract": "0xCcCCccccCCCCcCCCCCCcCcCccCcCCCcCcccccccC"
+  },
+  "message": {
+    "from": {"name": "Cow", "wallet": "0xCD2a3d9F938E13CD947Ec05AbC7FE734Df8DD826"},
+    "to": {"name": "Bob", "wallet": "0xbBbBBBBbbBBBbbbBbbBbbbbBBbBbbbbBbBbbBBbB"},
+    "contents": "Hello, Bob!"
+  }
+}`
+
+const (
+	eip712MailHash      = "0xbe609aee343fb3c4b28e1df9e632fca64fcfaede20f02e86244efddf30957bd2"
+	eip712MailSignature = "0x4355c47d63924e8a72e509b65029052eb6c299d53a04e167c5775fd466751c9d07299936d304c153f6443dfa05f40ff007d72911b6f72307f996231605b915621c"
+	eip712MailSigner    = "0xCD2a3d9F938E13CD947Ec05AbC7FE734Df8DD826"
+)
+
+// newCowManager 以 EIP-712 示例私钥创建钱包管理器
+func newCowManager(t *testing.T) *Manager {
+	t.Helper()
+	t.Setenv("PRIVATE_KEYS", hexutil.Encode(crypto.Keccak256([]byte("cow"))))
+	wm, err := NewKeyManager(filepath.Join(t.TempDir(), ".env"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := wm.GetAddressByIndex(0).Hex(); got != eip712MailSigner {
+		t.Fatalf("示例私钥地址为 %s，期望 %s", got, eip712MailSigner)
+	}
+	return wm
+}
+
+func TestSignTypedDataSpecVector(t *testing.T) {
+	wm := newCowManager(t)
+	typedData, err := ParseTypedData([]byte(eip712MailExample))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	signature, err := wm.SignTypedData(0, typedData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := hexutil.Encode(signature); got != eip712MailSignature {
+		t.Errorf("签名为 %s，期望 %s", got, eip712MailSignature)
+	}
+
+	signer, err := RecoverTypedDataSigner(typedData, signature)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if signer != common.HexToAddress(eip712MailSigner) {
+		t.Errorf("恢复的签名者为 %s，期望 %s", signer.Hex(), eip712MailSigner)
+	}
+
+	// 修改消息内容后签名者不再一致
+	typedData.Message["contents"] = "Hello, Alice!"
+	if signer, err := RecoverTypedDataSigner(typedData, signature); err == nil && signer == common.HexToAddress(eip712MailSigner) {
+		t.Errorf("消息被修改后仍恢复出原签名者")
+	}
+}
+
+func TestTypedDataHashSpecVector(t *testing.T) {
+	typedData, err := ParseTypedData([]byte(eip712MailExample))
+	if err != nil {
+		t.Fatal(err)
+	}
+	hash, _, err := apitypes.TypedDataAndHash(typedData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := hexutil.Encode(hash); got != eip712MailHash {
+		t.Errorf("签名哈希为 %s，期望 %s", got, eip712MailHash)
+	}
+
+	signature, err := ParseSignature(eip712MailSignature)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := RecoverTypedDataSigner(typedData, signature)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if signer != common.HexToAddress(eip712MailSigner) {
+		t.Errorf("恢复的签名者为 %s，期望 %s", signer.Hex(), eip712MailSigner)
+	}
+
+	if _, err := ParseTypedData([]byte(`{"types": {}, "message": {}}`)); err == nil {
+		t.Errorf("缺少 primaryType 时应报错")
+	}
+}
+
+func TestSignMessageRoundTrip(t *testing.T) {
+	wm := newCowManager(t)
+	message := []byte("hello")
+	signature, err := wm.SignMessage(0, message)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := signature[crypto.RecoveryIDOffset]; v != 27 && v != 28 {
+		t.Errorf("V 值为 %d，期望 27 或 28", v)
+	}
+
+	// 同时兼容 27/28 和 0/1 两种 V 值
+	for _, offset := range []byte{0, 27} {
+		sig := make([]byte, len(signature))
+		copy(sig, signature)
+		sig[crypto.RecoveryIDOffset] = signature[crypto.RecoveryIDOffset] - 27 + offset
+		signer, err := RecoverMessageSigner(message, sig)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if signer != common.HexToAddress(eip712MailSigner) {
+			t.Errorf("V 偏移 %d: 恢复的签名者为 %s", offset, signer.Hex())
+		}
+	}
+
+	if _, err := ParseSignature("0x1234"); err == nil {
+		t.Errorf("签名长度错误时应报错")
+	}
+}
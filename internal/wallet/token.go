@@ -0,0 +1,112 @@
+package wallet
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// erc20ABIJSON ERC-20 标准接口
+const erc20ABIJSON = `[
+	{"type":"function","name":"name","stateMutability":"view","inputs":[],"outputs":[{"name":"","type":"string"}]},
+	{"type":"function","name":"symbol","stateMutability":"view","inputs":[],"outputs":[{"name":"","type":"string"}]},
+	{"type":"function","name":"decimals","stateMutability":"view","inputs":[],"outputs":[{"name":"","type":"uint8"}]},
+	{"type":"function","name":"totalSupply","stateMutability":"view","inputs":[],"outputs":[{"name":"","type":"uint256"}]},
+	{"type":"function","name":"balanceOf","stateMutability":"view","inputs":[{"name":"owner","type":"address"}],"outputs":[{"name":"","type":"uint256"}]},
+	{"type":"function","name":"allowance","stateMutability":"view","inputs":[{"name":"owner","type":"address"},{"name":"spender","type":"address"}],"outputs":[{"name":"","type":"uint256"}]},
+	{"type":"function","name":"transfer","stateMutability":"nonpayable","inputs":[{"name":"to","type":"address"},{"name":"value","type":"uint256"}],"outputs":[{"name":"","type":"bool"}]},
+	{"type":"function","name":"approve","stateMutability":"nonpayable","inputs":[{"name":"spender","type":"address"},{"name":"value","type":"uint256"}],"outputs":[{"name":"","type":"bool"}]},
+	{"type":"function","name":"transferFrom","stateMutability":"nonpayable","inputs":[{"name":"from","type":"address"},{"name":"to","type":"address"},{"name":"value","type":"uint256"}],"outputs":[{"name":"","type":"bool"}]},
+	{"type":"event","name":"Transfer","anonymous":false,"inputs":[{"name":"from","type":"address","indexed":true},{"name":"to","type":"address","indexed":true},{"name":"value","type":"uint256","indexed":false}]},
+	{"type":"event","name":"Approval","anonymous":false,"inputs":[{"name":"owner","type":"address","indexed":true},{"name":"spender","type":"address","indexed":true},{"name":"value","type":"uint256","indexed":false}]}
+]`
+
+// ERC20ABI ERC-20 标准接口ABI
+var ERC20ABI = mustParseABI(erc20ABIJSON)
+
+// TokenInfo ERC-20 代币信息
+type TokenInfo struct {
+	Address  common.Address
+	Symbol   string
+	Decimals int
+}
+
+// Format 按代币精度格式化金额
+func (t *TokenInfo) Format(value *big.Int) string {
+	return FormatUnits(value, t.Decimals)
+}
+
+// Parse 按代币精度解析金额
+func (t *TokenInfo) Parse(amountStr string) (*big.Int, error) {
+	return ParseUnits(amountStr, t.Decimals)
+}
+
+// GetTokenInfo 查询ERC-20代币的符号和精度
+func (m *Manager) GetTokenInfo(ctx context.Context, token common.Address) (*TokenInfo, error) {
+	var code []byte
+	err := m.pool.Do(ctx, func(ctx context.Context, client *ethclient.Client) (err error) {
+		code, err = client.CodeAt(ctx, token, nil)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("查询代币合约失败: %v", err)
+	}
+	if len(code) == 0 {
+		return nil, fmt.Errorf("地址 %s 上没有合约，请检查代币地址和网络", token.Hex())
+	}
+
+	symbolData, _ := ERC20ABI.Pack("symbol")
+	decimalsData, _ := ERC20ABI.Pack("decimals")
+	var symbolResult, decimalsResult hexutil.Bytes
+	elems := []rpc.BatchElem{
+		{Method: "eth_call", Args: []interface{}{toCallArg(ethereum.CallMsg{To: &token, Data: symbolData}), "latest"}, Result: &symbolResult},
+		{Method: "eth_call", Args: []interface{}{toCallArg(ethereum.CallMsg{To: &token, Data: decimalsData}), "latest"}, Result: &decimalsResult},
+	}
+	if err := m.pool.BatchCall(ctx, elems); err != nil {
+		return nil, fmt.Errorf("查询代币信息失败: %v", err)
+	}
+
+	if elems[1].Error != nil {
+		return nil, fmt.Errorf("地址 %s 不是有效的ERC-20合约: %v", token.Hex(), elems[1].Error)
+	}
+	values, err := ERC20ABI.Unpack("decimals", decimalsResult)
+	if err != nil || len(values) == 0 {
+		return nil, fmt.Errorf("地址 %s 不是有效的ERC-20合约: decimals 返回值无效", token.Hex())
+	}
+	info := &TokenInfo{Address: token, Decimals: int(values[0].(uint8))}
+
+	// 部分早期代币的 symbol 返回 bytes32 或未实现，此时使用缩写地址
+	info.Symbol = shortAddress(token)
+	if elems[0].Error == nil {
+		if values, err := ERC20ABI.Unpack("symbol", symbolResult); err == nil && len(values) > 0 {
+			if symbol := strings.TrimSpace(values[0].(string)); symbol != "" {
+				info.Symbol = symbol
+			}
+		}
+	}
+
+	return info, nil
+}
+
+// shortAddress 缩写地址，如 0x1234…abcd
+func shortAddress(address common.Address) string {
+	hex := address.Hex()
+	return hex[:6] + "…" + hex[len(hex)-4:]
+}
+
+// mustParseABI 解析内置ABI定义
+func mustParseABI(definition string) abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(definition))
+	if err != nil {
+		panic(fmt.Sprintf("内置ABI定义无效: %v", err))
+	}
+	return parsed
+}
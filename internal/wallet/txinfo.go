@@ -0,0 +1,192 @@
+package wallet
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// TxInfo 交易及其收据
+type TxInfo struct {
+	Tx      *types.Transaction
+	From    common.Address
+	Pending bool
+
+	// 以下字段仅在交易已打包时有效
+	Receipt       *types.Receipt
+	BlockTime     uint64
+	Confirmations uint64
+}
+
+// Mined 交易是否已打包
+func (t *TxInfo) Mined() bool {
+	return t.Receipt != nil
+}
+
+// Succeeded 交易是否已打包且执行成功
+func (t *TxInfo) Succeeded() bool {
+	return t.Receipt != nil && t.Receipt.Status == types.ReceiptStatusSuccessful
+}
+
+// Fee 实际支付的手续费，未打包时为空
+func (t *TxInfo) Fee() *big.Int {
+	if t.Receipt == nil || t.Receipt.EffectiveGasPrice == nil {
+		return nil
+	}
+	return new(big.Int).Mul(t.Receipt.EffectiveGasPrice, new(big.Int).SetUint64(t.Receipt.GasUsed))
+}
+
+// TokenTransfer 交易日志中的 ERC-20 Transfer 事件
+type TokenTransfer struct {
+	Token common.Address
+	Info  *TokenInfo // 查询代币信息失败时为空
+	From  common.Address
+	To    common.Address
+	Value *big.Int
+}
+
+// GetTxInfo 查询交易、收据、打包区块时间和确认数
+func (m *Manager) GetTxInfo(ctx context.Context, hash common.Hash) (*TxInfo, error) {
+	tx, pending, err := m.GetTransaction(ctx, hash)
+	if err != nil {
+		return nil, err
+	}
+	from, err := types.Sender(types.LatestSignerForChainID(m.GetChainID()), tx)
+	if err != nil {
+		return nil, fmt.Errorf("解析交易发送方失败: %v", err)
+	}
+	info := &TxInfo{Tx: tx, From: from, Pending: pending}
+	if pending {
+		return info, nil
+	}
+
+	receipts, err := m.GetReceipts(ctx, []common.Hash{hash})
+	if err != nil {
+		return nil, err
+	}
+	// 节点刚返回交易、尚未建立收据索引时按未打包处理
+	if receipts[0] == nil {
+		info.Pending = true
+		return info, nil
+	}
+	info.Receipt = receipts[0]
+
+	block, err := m.HeaderByNumber(ctx, info.Receipt.BlockNumber)
+	if err != nil {
+		return nil, err
+	}
+	latest, err := m.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	info.BlockTime = block.Time
+	if latest.Number.Cmp(block.Number) >= 0 {
+		info.Confirmations = new(big.Int).Sub(latest.Number, block.Number).Uint64() + 1
+	}
+	return info, nil
+}
+
+// 等待交易打包的参数
+const (
+	receiptPollInterval  = 2 * time.Second
+	receiptMaxQueryError = 10 // 连续查询失败次数上限
+)
+
+// WaitReceipt 等待交易打包并返回收据，ctx 取消时返回错误（交易仍可能稍后打包）
+func (m *Manager) WaitReceipt(ctx context.Context, hash common.Hash) (*types.Receipt, error) {
+	queryErrors := 0
+	for {
+		receipts, err := m.GetReceipts(ctx, []common.Hash{hash})
+		switch {
+		case err == nil && receipts[0] != nil:
+			return receipts[0], nil
+		case err == nil:
+			queryErrors = 0
+		case ctx.Err() == nil:
+			queryErrors++
+			if queryErrors >= receiptMaxQueryError {
+				return nil, err
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("等待交易 %s 打包时中断: %v", hash.Hex(), ctx.Err())
+		case <-time.After(receiptPollInterval):
+		}
+	}
+}
+
+// RevertReason 在打包区块的父区块状态上重放交易，解析回滚原因
+// 重放不包含同一区块内排在前面的交易，结果可能与实际执行不同
+// 重放得不到原因且交易用尽了全部Gas时，提示可能是Gas不足（invalid opcode 等错误同样会耗尽Gas）
+func (m *Manager) RevertReason(ctx context.Context, info *TxInfo) (string, error) {
+	if info.Receipt == nil {
+		return "", fmt.Errorf("交易尚未打包")
+	}
+	outOfGas := info.Receipt.GasUsed == info.Tx.Gas()
+
+	msg := ethereum.CallMsg{
+		From:  info.From,
+		To:    info.Tx.To(),
+		Gas:   info.Tx.Gas(),
+		Value: info.Tx.Value(),
+		Data:  info.Tx.Data(),
+	}
+	parent := new(big.Int).Sub(info.Receipt.BlockNumber, big.NewInt(1))
+	err := m.pool.Do(ctx, func(ctx context.Context, client *ethclient.Client) error {
+		_, err := client.CallContract(ctx, msg, parent)
+		return err
+	})
+	if err != nil {
+		if reason, ok := revertReason(err); ok {
+			return reason, nil
+		}
+	}
+
+	switch {
+	case outOfGas && (err == nil || isMissingStateError(err) || isTransientError(err)):
+		return "可能Gas耗尽（out of gas），交易用尽了全部Gas上限", nil
+	case err == nil:
+		return "", fmt.Errorf("重放未复现回滚，可能依赖同一区块内的其他交易")
+	case isMissingStateError(err):
+		return "", fmt.Errorf("RPC节点没有区块 %s 的历史状态，重放交易需要归档节点(archive node)", parent.String())
+	case isTransientError(err):
+		return "", fmt.Errorf("重放交易失败: %v", err)
+	}
+	return err.Error(), nil
+}
+
+// DecodeTokenTransfers 解析日志中的 ERC-20 Transfer 事件，并查询涉及代币的符号和精度
+// ERC-721 的 Transfer 事件 tokenId 为 indexed 参数（4个topic），不在此列
+func (m *Manager) DecodeTokenTransfers(ctx context.Context, logs []*types.Log) []TokenTransfer {
+	event := ERC20ABI.Events["Transfer"]
+	var transfers []TokenTransfer
+	tokens := make(map[common.Address]*TokenInfo)
+	for _, log := range logs {
+		if len(log.Topics) != 3 || log.Topics[0] != event.ID || len(log.Data) != 32 {
+			continue
+		}
+		transfer := TokenTransfer{
+			Token: log.Address,
+			From:  common.BytesToAddress(log.Topics[1].Bytes()),
+			To:    common.BytesToAddress(log.Topics[2].Bytes()),
+			Value: new(big.Int).SetBytes(log.Data),
+		}
+
+		info, ok := tokens[log.Address]
+		if !ok {
+			info, _ = m.GetTokenInfo(ctx, log.Address)
+			tokens[log.Address] = info
+		}
+		transfer.Info = info
+		transfers = append(transfers, transfer)
+	}
+	return transfers
+}